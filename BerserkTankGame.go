@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"database/sql"
+	"flag"
 	_ "fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
@@ -17,12 +18,16 @@ import (
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	_ "golang.org/x/image/font/opentype"
+	"image"
 	"image/color"
 	_ "image/png"
+	"io"
 	"log"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -206,22 +211,44 @@ func init() {
 }
 
 type Sprite struct {
-	upPict              *ebiten.Image
-	downPict            *ebiten.Image
-	leftPict            *ebiten.Image
-	rightPict           *ebiten.Image
-	xLoc                int
-	yLoc                int
-	dx                  int
-	dy                  int
-	width               float64
-	height              float64
-	collision           bool
-	direction           string
-	health              int
-	inPlayerProximity   bool
-	projectileHold      bool
-	enemyProjectileList []Sprite
+	upPict            *ebiten.Image
+	downPict          *ebiten.Image
+	leftPict          *ebiten.Image
+	rightPict         *ebiten.Image
+	xLoc              int
+	yLoc              int
+	dx                int
+	dy                int
+	width             float64
+	height            float64
+	collision         bool
+	direction         string
+	health            int
+	inPlayerProximity bool
+	projectileHold    bool
+	angle             float64
+	xPos              float64
+	yPos              float64
+	xVel              float64
+	yVel              float64
+	speed             float64
+	damagePicts       []*ebiten.Image
+	xPrev             int
+	yPrev             int
+	wallContact       bool
+	ricochets         int
+	hitbox            image.Rectangle
+	enemyType         string
+	upMask            *alphaMask
+	downMask          *alphaMask
+	leftMask          *alphaMask
+	rightMask         *alphaMask
+	pixelPerfect      bool
+	indestructible    bool
+	shooter           int
+	canUseDoors       bool
+	killedBy          string
+	baited            bool
 }
 
 type Game struct {
@@ -230,7 +257,10 @@ type Game struct {
 	monsterEnemy                       Sprite
 	tankTopper                         Sprite
 	fireball                           Sprite
+	heavyFireball                      Sprite
+	impactList                         []Sprite
 	coinSprite                         Sprite
+	wallBlock                          Sprite
 	heartSprite1                       Sprite
 	heartSprite2                       Sprite
 	heartSprite3                       Sprite
@@ -238,10 +268,20 @@ type Game struct {
 	firstMap                           Sprite
 	secondMap                          Sprite
 	thirdMap                           Sprite
+	generatedMap                       Sprite
 	winnerScreen                       Sprite
 	loserScreen                        Sprite
 	drawOps                            ebiten.DrawImageOptions
-	collectedGold                      bool
+	collectibleSprites                 []Sprite
+	collectibles                       []collectible
+	collectibleSpots                   []image.Point
+	goldCollected                      int
+	itemsCollected                     int
+	levelTicks                         int
+	runTicks                           int
+	splits                             []int
+	bestSplits                         map[int]int
+	entrance                           roomDoor
 	playerAndWallCollision             bool
 	projectileAndWallCollision         bool
 	mostRecentKeyLeft                  bool
@@ -254,16 +294,53 @@ type Game struct {
 	mostRecentKeyW                     bool
 	deathCounter                       int
 	score                              int
-	projectileList                     []Sprite
+	playerProjectiles                  projectilePool
+	enemyProjectiles                   projectilePool
+	enemyGrid                          *spatialHash
+	enemyProjectileGrid                *spatialHash
 	levelOneEnemyList                  []Sprite
 	levelTwoEnemyList                  []Sprite
 	levelThreeEnemyList                []Sprite
+	generatedEnemyList                 []Sprite
+	generatedLayout                    generatedRoom
+	generatedRooms                     int
+	endlessSeed                        int64
+	endlessMode                        bool
+	combo                              int
+	comboTicks                         int
+	maxCombo                           int
+	levelShotsFired                    int
+	levelShotsHit                      int
+	levelDamage                        int
+	showingResults                     bool
+	results                            []bonusTally
+	resultsTicks                       int
+	resultsDoor                        roomDoor
+	unlocked                           map[string]bool
+	killTicks                          []int
+	toasts                             []string
+	toastTicks                         int
+	showAchievements                   bool
+	checkpoint                         campaignCheckpoint
+	checkpointName                     string
+	hasCheckpoint                      bool
+	continued                          bool
+	levelReached                       int
+	splitLevels                        []int
+	shotsFired                         int
+	shotsHit                           int
+	killsByType                        map[string]int
+	deaths                             map[string]int
+	statsDismissed                     bool
+	blockList                          []Sprite
 	levelOneIsActive                   bool
 	levelTwoIsActive                   bool
 	levelThreeIsActive                 bool
+	generatedRoomIsActive              bool
 	spawnedLevel1Enemies               bool
 	spawnedLevel2Enemies               bool
 	spawnedLevel3Enemies               bool
+	spawnedGeneratedRoom               bool
 	gameOver                           bool
 	gameWon                            bool
 	userNameList                       []string
@@ -285,59 +362,463 @@ type Game struct {
 	extraLifeAudioPlayer               *audio.Player
 	playedWinSound                     bool
 	playedLoseSound                    bool
-	extraLifeAwarded                   bool
-	allScores                          bool
+	nextLifeScore                      int
+	bannerTicks                        int
+	bannerText                         string
 	playerScores                       bool
-	currentPlayerAndScoreLeaderboard   bool
+	leaderboardTab                     int
+	difficultyFilter                   int
+	modeFilter                         int
 	playerRespawnInvincibility         bool
+	mouseAim                           bool
+	tankControls                       bool
+	solidWalls                         bool
+	ricochetShells                     bool
+	friendlyFire                       bool
+	kills                              map[string]int
+	turretAngle                        float64
 }
 
 var g Game
+
+// projectilePool holds the live shells of one side. Spent shells are pruned every tick and their
+// slots are reused by later shots, so the backing array never grows past the capacity.
+type projectilePool struct {
+	live     []Sprite
+	capacity int
+}
+
+func newProjectilePool(capacity int) projectilePool {
+	return projectilePool{live: make([]Sprite, 0, capacity), capacity: capacity}
+}
+
+func (pool *projectilePool) hasRoom() bool {
+	return len(pool.live) < pool.capacity
+}
+
+func (pool *projectilePool) fire(projectile Sprite) {
+	if pool.hasRoom() {
+		pool.live = append(pool.live, projectile)
+	}
+}
+
+func (pool *projectilePool) prune() {
+	live := pool.live[:0]
+	for i := 0; i < len(pool.live); i++ {
+		if pool.live[i].collision == false {
+			live = append(live, pool.live[i])
+		}
+	}
+	pool.live = live
+}
+
+func (pool *projectilePool) clear() {
+	pool.live = pool.live[:0]
+}
+
+// spatialHash is a uniform grid broadphase. Entities are inserted by their bounding box into every
+// cell the box touches, and query returns only the ids whose boxes overlap the queried area, so the
+// narrowphase collision functions only run on nearby pairs. The grid is rebuilt every tick.
+type spatialHash struct {
+	cellSize int
+	cells    map[image.Point][]int
+	bounds   []image.Rectangle
+	visited  []int
+	queryID  int
+	found    []int
+}
+
+func newSpatialHash(cellSize int) *spatialHash {
+	return &spatialHash{cellSize: cellSize, cells: make(map[image.Point][]int)}
+}
+
+func (hash *spatialHash) reset() {
+	for cell, ids := range hash.cells {
+		hash.cells[cell] = ids[:0]
+	}
+	hash.bounds = hash.bounds[:0]
+	hash.visited = hash.visited[:0]
+}
+
+func (hash *spatialHash) cellRange(area image.Rectangle) (image.Point, image.Point) {
+	minCell := image.Pt(floorDiv(area.Min.X, hash.cellSize), floorDiv(area.Min.Y, hash.cellSize))
+	maxCell := image.Pt(floorDiv(area.Max.X-1, hash.cellSize), floorDiv(area.Max.Y-1, hash.cellSize))
+	return minCell, maxCell
+}
+
+func (hash *spatialHash) insert(id int, bounds image.Rectangle) {
+	for len(hash.bounds) <= id {
+		hash.bounds = append(hash.bounds, image.Rectangle{})
+		hash.visited = append(hash.visited, 0)
+	}
+	hash.bounds[id] = bounds
+	minCell, maxCell := hash.cellRange(bounds)
+	for cellY := minCell.Y; cellY <= maxCell.Y; cellY++ {
+		for cellX := minCell.X; cellX <= maxCell.X; cellX++ {
+			cell := image.Pt(cellX, cellY)
+			hash.cells[cell] = append(hash.cells[cell], id)
+		}
+	}
+}
+
+// query returns the ids overlapping area in ascending order. The returned slice is reused by the
+// next call to query.
+func (hash *spatialHash) query(area image.Rectangle) []int {
+	hash.queryID++
+	hash.found = hash.found[:0]
+	minCell, maxCell := hash.cellRange(area)
+	for cellY := minCell.Y; cellY <= maxCell.Y; cellY++ {
+		for cellX := minCell.X; cellX <= maxCell.X; cellX++ {
+			for _, id := range hash.cells[image.Pt(cellX, cellY)] {
+				if hash.visited[id] != hash.queryID {
+					hash.visited[id] = hash.queryID
+					if hash.bounds[id].Overlaps(area) {
+						hash.found = append(hash.found, id)
+					}
+				}
+			}
+		}
+	}
+	sort.Ints(hash.found)
+	return hash.found
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+// hitboxAt returns the hitbox of a sprite drawn with its top left corner at x, y. Hitboxes are
+// declared against the up facing picture and turned to match the way the sprite is facing.
+func hitboxAt(anySprite Sprite, x int, y int) image.Rectangle {
+	box := anySprite.hitbox
+	if anySprite.upPict != nil && anySprite.direction != "" && anySprite.direction != "up" {
+		w, h := anySprite.upPict.Size()
+		if anySprite.direction == "right" {
+			box = image.Rect(h-box.Max.Y, box.Min.X, h-box.Min.Y, box.Max.X)
+		} else if anySprite.direction == "left" {
+			box = image.Rect(box.Min.Y, w-box.Max.X, box.Max.Y, w-box.Min.X)
+		} else if anySprite.direction == "down" {
+			box = image.Rect(w-box.Max.X, h-box.Max.Y, w-box.Min.X, h-box.Min.Y)
+		}
+	}
+	return box.Add(image.Pt(x, y))
+}
+
+func hitboxBounds(anySprite Sprite) image.Rectangle {
+	return hitboxAt(anySprite, anySprite.xLoc, anySprite.yLoc)
+}
+
+// alphaMask records which pixels of a sprite picture are opaque enough to be hit.
+type alphaMask struct {
+	width  int
+	height int
+	opaque []bool
+}
+
+func newAlphaMask(picture image.Image) *alphaMask {
+	bounds := picture.Bounds()
+	mask := &alphaMask{width: bounds.Dx(), height: bounds.Dy(), opaque: make([]bool, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < mask.height; y++ {
+		for x := 0; x < mask.width; x++ {
+			_, _, _, alpha := picture.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			mask.opaque[y*mask.width+x] = alpha >= 0x8000
+		}
+	}
+	return mask
+}
+
+func (mask *alphaMask) opaqueAt(x int, y int) bool {
+	if x < 0 || y < 0 || x >= mask.width || y >= mask.height {
+		return false
+	}
+	return mask.opaque[y*mask.width+x]
+}
+
+func spriteMask(anySprite Sprite) *alphaMask {
+	if anySprite.pixelPerfect == false || anySprite.direction == "rotated" {
+		return nil
+	}
+	if anySprite.direction == "left" {
+		return anySprite.leftMask
+	} else if anySprite.direction == "right" {
+		return anySprite.rightMask
+	} else if anySprite.direction == "down" {
+		return anySprite.downMask
+	}
+	return anySprite.upMask
+}
+
+// spritesTouch reports whether two sprites collide. Once their hitboxes overlap, sprites that have
+// pixel perfect collision switched on are only hit where their picture is opaque.
+func spritesTouch(a Sprite, b Sprite) bool {
+	overlap := hitboxBounds(a).Intersect(hitboxBounds(b))
+	if overlap.Empty() {
+		return false
+	}
+	maskA, maskB := spriteMask(a), spriteMask(b)
+	if maskA == nil && maskB == nil {
+		return true
+	}
+	for y := overlap.Min.Y; y < overlap.Max.Y; y++ {
+		for x := overlap.Min.X; x < overlap.Max.X; x++ {
+			if (maskA == nil || maskA.opaqueAt(x-a.xLoc, y-a.yLoc)) &&
+				(maskB == nil || maskB.opaqueAt(x-b.xLoc, y-b.yLoc)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (game *Game) rebuildEnemyGrid(enemyList []Sprite) {
+	game.enemyGrid.reset()
+	for j := 0; j < len(enemyList); j++ {
+		if enemyList[j].collision == false {
+			game.enemyGrid.insert(j, hitboxBounds(enemyList[j]))
+		}
+	}
+}
+
+func (game *Game) rebuildEnemyProjectileGrid() {
+	game.enemyProjectileGrid.reset()
+	for i := 0; i < len(game.enemyProjectiles.live); i++ {
+		if game.enemyProjectiles.live[i].collision == false {
+			game.enemyProjectileGrid.insert(i, sweptBounds(game.enemyProjectiles.live[i]))
+		}
+	}
+}
+
 var userNameMap = make(map[int][]string)
 var scoreMap = make(map[int][]int)
-var currentPlayerMap = make(map[int][]string)
-var currentPlayerScoreMap = make(map[int][]int)
+var comboMap = make(map[int][]int)
+var continuedMap = make(map[int][]bool)
 var dbUserNameList []string
 var dbUserNameListSorted []string
 var dbScoreList []int
 var dbScoreListSorted []int
 
-func (game *Game) gotGold(player, gold Sprite) bool {
-	goldWidth, goldHeight := gold.upPict.Size()
-	playerWidth, playerHeight := player.upPict.Size()
-	if player.xLoc < gold.xLoc+goldWidth &&
-		player.xLoc+playerWidth > gold.xLoc &&
-		player.yLoc < gold.yLoc+goldHeight &&
-		player.yLoc+playerHeight > gold.yLoc {
-		if game.score%100 == 0 {
-			g.pickedUpBonusAudioPlayer.Rewind()
-			g.pickedUpBonusAudioPlayer.Play()
-		} else if game.score%100 != 0 {
-			g.extraLifeAudioPlayer.Rewind()
-			g.extraLifeAudioPlayer.Play()
-			if game.deathCounter > 0 {
-				game.deathCounter -= 1
-				game.extraLifeAwarded = true
+// collectibleType describes one kind of pickup. Items with a respawn time come back somewhere else
+// in the room that long after being picked up; the others are gone for good. Gold counts towards
+// extra lives. Each kind is drawn as a scaled and recoloured version of the gold coins.
+type collectibleType struct {
+	name           string
+	points         int
+	perRoom        int
+	respawnSeconds int
+	gold           bool
+	scale          float64
+	hue            float64
+	saturation     float64
+}
+
+var collectibleTypes = []collectibleType{
+	{name: "gold", points: 25, perRoom: 1, respawnSeconds: 0, gold: true, scale: 1, hue: 0, saturation: 1},
+	{name: "silver", points: 10, perRoom: 2, respawnSeconds: 12, scale: 0.6, hue: 0, saturation: 0},
+	{name: "gem", points: 100, perRoom: 1, respawnSeconds: 30, scale: 0.5, hue: math.Pi, saturation: 1.5},
+}
+
+type collectible struct {
+	sprite       Sprite
+	kind         int
+	collected    bool
+	respawnTicks int
+}
+
+const navCellSize = 25
+
+// reachableSpots returns the centers of the nav grid cells the tank can drive to from where it is
+// now, going around walls and unbroken blocks. A cell counts as open if the tank fits on it facing
+// any way.
+func (game *Game) reachableSpots() []image.Point {
+	boundaryWidth := 25
+	playerBox := hitboxBounds(game.playerSprite)
+	size := int(math.Max(float64(playerBox.Dx()), float64(playerBox.Dy())))
+	walls := game.currentRoom().walls
+	cellCenter := func(cell image.Point) image.Point {
+		return image.Pt(boundaryWidth+cell.X*navCellSize+navCellSize/2, boundaryWidth+cell.Y*navCellSize+navCellSize/2)
+	}
+	open := func(cell image.Point) bool {
+		center := cellCenter(cell)
+		box := image.Rect(center.X-size/2, center.Y-size/2, center.X+size/2, center.Y+size/2)
+		return outsideArena(box) == false && overlapsAny(box, walls) == false && game.blockCollision(box) == false
+	}
+	columns := (ScreenWidth - boundaryWidth*2) / navCellSize
+	rows := (ScreenHeight - boundaryWidth*2) / navCellSize
+	playerCenter := image.Pt((playerBox.Min.X+playerBox.Max.X)/2, (playerBox.Min.Y+playerBox.Max.Y)/2)
+	start := image.Pt((playerCenter.X-boundaryWidth)/navCellSize, (playerCenter.Y-boundaryWidth)/navCellSize)
+	start.X = int(math.Max(0, math.Min(float64(start.X), float64(columns-1))))
+	start.Y = int(math.Max(0, math.Min(float64(start.Y), float64(rows-1))))
+
+	var spots []image.Point
+	seen := map[image.Point]bool{start: true}
+	queue := []image.Point{start}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		if open(cell) {
+			spots = append(spots, cellCenter(cell))
+		} else if cell != start {
+			continue
+		}
+		for _, step := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			next := cell.Add(step)
+			if next.X >= 0 && next.Y >= 0 && next.X < columns && next.Y < rows && seen[next] == false {
+				seen[next] = true
+				queue = append(queue, next)
 			}
 		}
-		game.score += 25
-		return true
 	}
-	return false
+	return spots
 }
 
-func wallCollisionCheckFirstLevel(anySprite Sprite, spriteWidth int) bool {
-	boundaryWidth := 25
-	if anySprite.xLoc < 0+boundaryWidth || anySprite.xLoc > ScreenWidth-boundaryWidth-spriteWidth ||
-		anySprite.yLoc > ScreenHeight-boundaryWidth-spriteWidth || anySprite.yLoc < 0+boundaryWidth ||
-		anySprite.xLoc > 200-spriteWidth && anySprite.xLoc < 275 && anySprite.yLoc < 250 ||
-		anySprite.xLoc > 275-spriteWidth && anySprite.xLoc < 475 && anySprite.yLoc < 250 && anySprite.yLoc > 175-spriteWidth ||
-		anySprite.xLoc > 175-spriteWidth && anySprite.xLoc < 275 && anySprite.yLoc < 475 && anySprite.yLoc > 400-spriteWidth ||
-		anySprite.xLoc > 550-spriteWidth && anySprite.xLoc < 625 && anySprite.yLoc < 575 && anySprite.yLoc > 350-spriteWidth ||
-		anySprite.xLoc > 475-spriteWidth && anySprite.xLoc < 550 && anySprite.yLoc < 575 && anySprite.yLoc > 500-spriteWidth {
-		return true
+// spawnCollectibles fills the room the player just entered with pickups, only on spots the player
+// can reach and not right next to where they start.
+func (game *Game) spawnCollectibles() {
+	playerBox := hitboxBounds(game.playerSprite)
+	playerCenter := image.Pt((playerBox.Min.X+playerBox.Max.X)/2, (playerBox.Min.Y+playerBox.Max.Y)/2)
+	game.collectibleSpots = nil
+	for _, spot := range game.reachableSpots() {
+		if math.Hypot(float64(spot.X-playerCenter.X), float64(spot.Y-playerCenter.Y)) > 150 {
+			game.collectibleSpots = append(game.collectibleSpots, spot)
+		}
+	}
+	game.collectibles = nil
+	for kind := 0; kind < len(collectibleTypes); kind++ {
+		for n := 0; n < collectibleTypes[kind].perRoom; n++ {
+			item := collectible{sprite: game.collectibleSprites[kind], kind: kind}
+			if game.placeCollectible(&item) {
+				game.collectibles = append(game.collectibles, item)
+			}
+		}
+	}
+}
+
+// placeCollectible moves an item to a random reachable spot, trying not to put it on top of
+// another item. It reports false if the room has nowhere to put it.
+func (game *Game) placeCollectible(item *collectible) bool {
+	if len(game.collectibleSpots) == 0 {
+		return false
+	}
+	box := hitboxAt(item.sprite, 0, 0)
+	for try := 0; try < 20; try++ {
+		spot := game.collectibleSpots[rand.Intn(len(game.collectibleSpots))]
+		item.sprite.xLoc = spot.X - (box.Min.X+box.Max.X)/2
+		item.sprite.yLoc = spot.Y - (box.Min.Y+box.Max.Y)/2
+		overlapping := false
+		for i := 0; i < len(game.collectibles); i++ {
+			other := game.collectibles[i]
+			if &game.collectibles[i] != item && other.collected == false && hitboxBounds(other.sprite).Overlaps(hitboxBounds(item.sprite)) {
+				overlapping = true
+			}
+		}
+		if overlapping == false {
+			break
+		}
+	}
+	return true
+}
+
+// collectItems picks up any item the player is driving over.
+func (game *Game) collectItems() {
+	playerBox := hitboxBounds(game.playerSprite)
+	for i := 0; i < len(game.collectibles); i++ {
+		if game.collectibles[i].collected == false && playerBox.Overlaps(hitboxBounds(game.collectibles[i].sprite)) {
+			game.pickUp(&game.collectibles[i])
+		}
+	}
+}
+
+func (game *Game) pickUp(item *collectible) {
+	itemType := collectibleTypes[item.kind]
+	item.collected = true
+	item.respawnTicks = itemType.respawnSeconds * ebiten.MaxTPS()
+	game.itemsCollected += 1
+	game.score += itemType.points
+	extraLife := false
+	if itemType.gold == true {
+		game.goldCollected += 1
+		extraLife = lifeRules.goldEvery > 0 && game.goldCollected%lifeRules.goldEvery == 0 && game.grantLife()
+	}
+	if extraLife == false {
+		g.pickedUpBonusAudioPlayer.Rewind()
+		g.pickedUpBonusAudioPlayer.Play()
+	}
+}
+
+// manageCollectibles counts down the respawn timers of picked up items and puts them back.
+func (game *Game) manageCollectibles() {
+	for i := 0; i < len(game.collectibles); i++ {
+		item := &game.collectibles[i]
+		if item.collected == false || item.respawnTicks == 0 {
+			continue
+		}
+		item.respawnTicks -= 1
+		if item.respawnTicks == 0 {
+			game.placeCollectible(item)
+			item.collected = false
+		}
+	}
+}
+
+// extraLifeRules decides when the player earns a life back. scoreEvery and goldEvery can be set to 0
+// to turn that way of earning lives off. The player starts with 3 lives and never holds more than
+// maxLives; a life earned at the cap is paid out as fullBonus points instead.
+type extraLifeRules struct {
+	scoreEvery int
+	goldEvery  int
+	maxLives   int
+	fullBonus  int
+}
+
+var lifeRules = extraLifeRules{scoreEvery: 5000, goldEvery: 2, maxLives: 5, fullBonus: 1000}
+
+const (
+	startingLives = 3
+	bannerTicks   = 120
+)
+
+func (game *Game) lives() int {
+	return startingLives - game.deathCounter
+}
+
+// grantLife gives the player a life, or the full lives bonus if they are already at the cap, and
+// reports whether it gave a life.
+func (game *Game) grantLife() bool {
+	if game.lives() >= lifeRules.maxLives {
+		if lifeRules.fullBonus > 0 {
+			game.score += lifeRules.fullBonus
+			game.bannerText = "LIVES FULL +" + strconv.Itoa(lifeRules.fullBonus)
+			game.bannerTicks = bannerTicks
+		}
+		return false
+	}
+	game.deathCounter -= 1
+	game.bannerText = "EXTRA LIFE"
+	game.bannerTicks = bannerTicks
+	g.extraLifeAudioPlayer.Rewind()
+	g.extraLifeAudioPlayer.Play()
+	return true
+}
+
+// manageExtraLives awards a life each time the score passes another scoreEvery points, and runs
+// down the extra life banner.
+func (game *Game) manageExtraLives() {
+	if game.bannerTicks > 0 {
+		game.bannerTicks -= 1
+	}
+	if lifeRules.scoreEvery <= 0 {
+		return
+	}
+	if game.nextLifeScore == 0 {
+		game.nextLifeScore = lifeRules.scoreEvery
+	}
+	for game.score >= game.nextLifeScore {
+		game.nextLifeScore += lifeRules.scoreEvery
+		game.grantLife()
 	}
-	return false
 }
 
 func (game *Game) iterateAndStoreUserName() {
@@ -347,19 +828,42 @@ func (game *Game) iterateAndStoreUserName() {
 }
 
 func (game *Game) getLeaderBoardFormat() {
-	if inpututil.IsKeyJustReleased(ebiten.KeySpace) {
-		if game.allScores == true && game.playerScores == false {
-			game.allScores = false
-			game.playerScores = true
-		} else if game.allScores == false && game.playerScores == true {
-			game.allScores = true
-			game.playerScores = false
-		} else {
-			game.allScores = true
-			game.playerScores = false
-		}
-		game.currentPlayerAndScoreLeaderboard = false
+	if game.statsDismissed == false {
+		game.statsDismissed = inpututil.IsKeyJustReleased(ebiten.KeyEnter)
+		return
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyA) {
+		game.showAchievements = !game.showAchievements
+	}
+	changed := true
+	if inpututil.IsKeyJustReleased(ebiten.KeyLeft) {
+		game.leaderboardTab = (game.leaderboardTab + len(leaderboardTabs) - 1) % len(leaderboardTabs)
+	} else if inpututil.IsKeyJustReleased(ebiten.KeyRight) {
+		game.leaderboardTab = (game.leaderboardTab + 1) % len(leaderboardTabs)
+	} else if inpututil.IsKeyJustReleased(ebiten.KeyD) {
+		game.difficultyFilter = (game.difficultyFilter + 1) % len(difficultyFilters)
+	} else if inpututil.IsKeyJustReleased(ebiten.KeyM) {
+		game.modeFilter = (game.modeFilter + 1) % len(modeFilters)
+	} else if inpututil.IsKeyJustReleased(ebiten.KeyP) {
+		game.playerScores = !game.playerScores
+	} else {
+		changed = false
+	}
+	if changed == true {
+		game.processDBtoMaps()
+	}
+}
+
+var leaderboardTabs = []string{"Today", "This week", "All time"}
+var difficultyFilters = []string{"all", "normal", "easy"}
+var modeFilters = []string{"all", "campaign", "endless"}
+
+// mode is the kind of game being played, "campaign" or "endless".
+func (game *Game) mode() string {
+	if game.endlessMode == true {
+		return "endless"
 	}
+	return "campaign"
 }
 
 func (game *Game) getUserName() {
@@ -442,38 +946,158 @@ func (game *Game) getUserName() {
 			game.userName += game.userNameList[i]
 		}
 		game.startGame = true
+		game.loadBestSplits()
+		game.loadAchievements()
+	} else if inpututil.IsKeyJustReleased(ebiten.KeyF10) == true && game.hasCheckpoint == true {
+		for i := 0; i < len(game.userNameList); i++ {
+			game.userName += game.userNameList[i]
+		}
+		game.startGame = true
+		game.loadBestSplits()
+		game.loadAchievements()
+		game.continueCampaign()
 	}
 }
 
-func wallCollisionCheckSecondLevel(anySprite Sprite, spriteWidth int) bool {
+// levelOneWalls, levelTwoWalls and levelThreeWalls are the inner walls of each map, matching the
+// wall art in the level pictures.
+var levelOneWalls = []image.Rectangle{
+	image.Rect(200, 0, 275, 250),
+	image.Rect(275, 175, 475, 250),
+	image.Rect(175, 400, 275, 475),
+	image.Rect(550, 350, 625, 575),
+	image.Rect(475, 500, 550, 575),
+}
+
+var levelTwoWalls = []image.Rectangle{
+	image.Rect(200, 0, 325, 525),
+	image.Rect(500, 200, 600, ScreenHeight),
+}
+
+var levelThreeWalls = []image.Rectangle{
+	image.Rect(200, 175, ScreenWidth, 275),
+	image.Rect(200, 275, 325, 425),
+	image.Rect(200, 425, 625, 525),
+}
+
+func outsideArena(box image.Rectangle) bool {
 	boundaryWidth := 25
-	if anySprite.xLoc < 0+boundaryWidth || anySprite.xLoc > ScreenWidth-boundaryWidth-spriteWidth ||
-		anySprite.yLoc > ScreenHeight-boundaryWidth-spriteWidth || anySprite.yLoc < 0+boundaryWidth ||
-		anySprite.xLoc > 200-spriteWidth && anySprite.xLoc < 325 && anySprite.yLoc < 525 ||
-		anySprite.xLoc > 500-spriteWidth && anySprite.xLoc < 600 && anySprite.yLoc > 200-spriteWidth {
-		return true
+	return box.Min.X < 0+boundaryWidth || box.Max.X > ScreenWidth-boundaryWidth ||
+		box.Min.Y < 0+boundaryWidth || box.Max.Y > ScreenHeight-boundaryWidth
+}
+
+func overlapsAny(box image.Rectangle, walls []image.Rectangle) bool {
+	for i := 0; i < len(walls); i++ {
+		if box.Overlaps(walls[i]) {
+			return true
+		}
 	}
 	return false
 }
 
-func wallCollisionCheckThirdLevel(anySprite Sprite, spriteWidth int) bool {
-	boundaryWidth := 25
-	if anySprite.xLoc < 0+boundaryWidth || anySprite.xLoc > ScreenWidth-boundaryWidth-spriteWidth ||
-		anySprite.yLoc > ScreenHeight-boundaryWidth-spriteWidth || anySprite.yLoc < 0+boundaryWidth ||
-		(anySprite.xLoc > 200-spriteWidth && anySprite.yLoc > 175-spriteWidth && anySprite.yLoc < 275) ||
-		(anySprite.xLoc > 200-spriteWidth && anySprite.xLoc < 325 && anySprite.yLoc > 275-spriteWidth && anySprite.yLoc < 425) ||
-		(anySprite.xLoc > 200-spriteWidth && anySprite.xLoc < 625 && anySprite.yLoc > 425-spriteWidth && anySprite.yLoc < 525) {
+// wallCollisionCheckCurrentLevel reports whether a sprite touches the outer wall, an inner wall or a
+// block of the current room. Sprites that can use doors may pass through the room's open doorways.
+func (game *Game) wallCollisionCheckCurrentLevel(anySprite Sprite) bool {
+	box := hitboxBounds(anySprite)
+	if outsideArena(box) && (anySprite.canUseDoors == false || game.inOpenDoorway(box) == false) {
 		return true
 	}
+	return overlapsAny(box, game.currentRoom().walls) || game.blockCollision(box)
+}
+
+func (game *Game) blockCollision(box image.Rectangle) bool {
+	for i := 0; i < len(game.blockList); i++ {
+		if game.blockList[i].health > 0 && box.Overlaps(hitboxBounds(game.blockList[i])) {
+			return true
+		}
+	}
 	return false
 }
 
-func projectileCollisionWithEnemy(anyEnemy Sprite, anyProjectileSprite Sprite, enemyWidth int, projectileWidth int) (bool, bool, int, int) {
-	if (anyProjectileSprite.xLoc < anyEnemy.xLoc+enemyWidth &&
-		anyProjectileSprite.xLoc+projectileWidth > anyEnemy.xLoc &&
-		anyProjectileSprite.yLoc < anyEnemy.yLoc+enemyWidth &&
-		anyProjectileSprite.yLoc+projectileWidth > anyEnemy.yLoc) && (anyEnemy.health == 1) {
-		if enemyWidth == 50 {
+func (game *Game) projectileHitsBlock(anyProjectileSprite Sprite) bool {
+	projectileBounds := hitboxBounds(anyProjectileSprite)
+	for i := 0; i < len(game.blockList); i++ {
+		if game.blockList[i].health > 0 && projectileBounds.Overlaps(hitboxBounds(game.blockList[i])) {
+			game.blockList[i].health -= 1
+			g.enemyAndPlayerCollisionAudioPlayer.Rewind()
+			g.enemyAndPlayerCollisionAudioPlayer.Play()
+			return true
+		}
+	}
+	return false
+}
+
+func (game *Game) placeBlocks(locations []image.Point) {
+	game.blockList = nil
+	for i := 0; i < len(locations); i++ {
+		block := game.wallBlock
+		block.xLoc = locations[i].X
+		block.yLoc = locations[i].Y
+		block.health = len(game.wallBlock.damagePicts)
+		game.blockList = append(game.blockList, block)
+	}
+}
+
+func (game *Game) activeEnemyList() []Sprite {
+	if game.levelTwoIsActive {
+		return game.levelTwoEnemyList
+	} else if game.levelThreeIsActive {
+		return game.levelThreeEnemyList
+	} else if game.generatedRoomIsActive {
+		return game.generatedEnemyList
+	}
+	return game.levelOneEnemyList
+}
+
+// slideAgainstWalls undoes the part of a move from oldX, oldY that would put the sprite inside a wall,
+// keeping whichever axis is still free so the sprite slides along the wall.
+func (game *Game) slideAgainstWalls(anySprite *Sprite, oldX int, oldY int) {
+	if game.wallCollisionCheckCurrentLevel(*anySprite) == false {
+		return
+	}
+	newX, newY := anySprite.xLoc, anySprite.yLoc
+	anySprite.xLoc, anySprite.yLoc = oldX, oldY
+	if game.wallCollisionCheckCurrentLevel(*anySprite) == true {
+		//already stuck inside a wall, let the sprite move out of it
+		anySprite.xLoc, anySprite.yLoc = newX, newY
+		return
+	}
+	anySprite.xLoc = newX
+	if game.wallCollisionCheckCurrentLevel(*anySprite) == false {
+		return
+	}
+	anySprite.xLoc, anySprite.yLoc = oldX, newY
+	if game.wallCollisionCheckCurrentLevel(*anySprite) == false {
+		return
+	}
+	anySprite.xLoc, anySprite.yLoc = oldX, oldY
+}
+
+func (game *Game) enemyLocations() []image.Point {
+	enemyList := game.activeEnemyList()
+	locations := make([]image.Point, len(enemyList))
+	for i := 0; i < len(enemyList); i++ {
+		locations[i] = image.Pt(enemyList[i].xLoc, enemyList[i].yLoc)
+	}
+	return locations
+}
+
+func (game *Game) resolveEnemyWallMovement(previousLocations []image.Point) {
+	if game.wallsSolid() == false {
+		return
+	}
+	enemyList := game.activeEnemyList()
+	for i := 0; i < len(enemyList) && i < len(previousLocations); i++ {
+		if enemyList[i].collision == false {
+			game.slideAgainstWalls(&enemyList[i], previousLocations[i].X, previousLocations[i].Y)
+		}
+	}
+}
+
+func projectileCollisionWithEnemy(anyEnemy Sprite, anyProjectileSprite Sprite) (bool, bool, int, int) {
+	hit := spritesTouch(anyProjectileSprite, anyEnemy)
+	if hit && (anyEnemy.health == 1) {
+		if anyEnemy.enemyType == "monster" {
 			g.monsterEnemyDeathAudioPlayer.Rewind()
 			g.monsterEnemyDeathAudioPlayer.Play()
 		} else {
@@ -483,10 +1107,7 @@ func projectileCollisionWithEnemy(anyEnemy Sprite, anyProjectileSprite Sprite, e
 		anyEnemy.health -= 1
 		additionalScore := 200
 		return true, true, anyEnemy.health, additionalScore
-	} else if (anyProjectileSprite.xLoc < anyEnemy.xLoc+enemyWidth &&
-		anyProjectileSprite.xLoc+projectileWidth > anyEnemy.xLoc &&
-		anyProjectileSprite.yLoc < anyEnemy.yLoc+enemyWidth &&
-		anyProjectileSprite.yLoc+projectileWidth > anyEnemy.yLoc) && (anyEnemy.health == 2) {
+	} else if hit && (anyEnemy.health == 2) {
 		g.monsterEnemyDamagedAudioPlayer.Rewind()
 		g.monsterEnemyDamagedAudioPlayer.Play()
 		anyEnemy.health -= 1
@@ -497,21 +1118,15 @@ func projectileCollisionWithEnemy(anyEnemy Sprite, anyProjectileSprite Sprite, e
 	return false, false, anyEnemy.health, additionalScore
 }
 
-func projectileCollisionWithPlayer(player Sprite, anyProjectileSprite Sprite, playerWidth int, projectileWidth int) (bool, int) {
-	if anyProjectileSprite.xLoc < player.xLoc+playerWidth &&
-		anyProjectileSprite.xLoc+projectileWidth > player.xLoc &&
-		anyProjectileSprite.yLoc < player.yLoc+playerWidth &&
-		anyProjectileSprite.yLoc+projectileWidth > player.yLoc {
+func projectileCollisionWithPlayer(player Sprite, anyProjectileSprite Sprite) (bool, int) {
+	if spritesTouch(anyProjectileSprite, player) {
 		return true, 1
 	}
 	return false, 0
 }
 
-func playerCollisionWithEnemy(anyEnemy Sprite, player Sprite, enemyWidth int, playerWidth int) int {
-	if player.xLoc < anyEnemy.xLoc+enemyWidth &&
-		player.xLoc+playerWidth > anyEnemy.xLoc &&
-		player.yLoc < anyEnemy.yLoc+enemyWidth &&
-		player.yLoc+playerWidth > anyEnemy.yLoc {
+func playerCollisionWithEnemy(anyEnemy Sprite, player Sprite) int {
+	if spritesTouch(player, anyEnemy) {
 		death := 1
 		return death
 	}
@@ -519,8 +1134,359 @@ func playerCollisionWithEnemy(anyEnemy Sprite, player Sprite, enemyWidth int, pl
 	return death
 }
 
-func (game *Game) playerShootFireball() []Sprite {
-	if inpututil.IsKeyJustReleased(ebiten.KeySpace) && game.playerSprite.projectileHold == false {
+func (game *Game) getSettings() {
+	if inpututil.IsKeyJustReleased(ebiten.KeyF1) {
+		game.mouseAim = !game.mouseAim
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF2) {
+		game.tankControls = !game.tankControls
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF3) {
+		game.solidWalls = !game.solidWalls
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF4) {
+		game.ricochetShells = !game.ricochetShells
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF5) {
+		game.playerSprite.pixelPerfect = !game.playerSprite.pixelPerfect
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF6) {
+		game.personEnemy.pixelPerfect = !game.personEnemy.pixelPerfect
+		game.monsterEnemy.pixelPerfect = game.personEnemy.pixelPerfect
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF7) {
+		game.fireball.pixelPerfect = !game.fireball.pixelPerfect
+		game.heavyFireball.pixelPerfect = game.fireball.pixelPerfect
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF8) {
+		game.friendlyFire = !game.friendlyFire
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyF9) {
+		game.endlessMode = !game.endlessMode
+	}
+}
+
+func collisionModeName(pixelPerfect bool) string {
+	if pixelPerfect == true {
+		return "Pixel perfect"
+	}
+	return "Bounding box"
+}
+
+func (game *Game) tankCenter() (float64, float64) {
+	playerWidth, playerHeight := game.playerSprite.upPict.Size()
+	return float64(game.playerSprite.xLoc) + float64(playerWidth)/2, float64(game.playerSprite.yLoc) + float64(playerHeight)/2
+}
+
+func moveProjectile(projectile *Sprite) {
+	projectile.xPrev, projectile.yPrev = projectile.xLoc, projectile.yLoc
+	if projectile.xVel != 0 || projectile.yVel != 0 {
+		//angled projectiles keep a float position so they follow the exact firing angle
+		projectile.xPos += projectile.xVel
+		projectile.yPos += projectile.yVel
+		projectile.xLoc = int(math.Round(projectile.xPos))
+		projectile.yLoc = int(math.Round(projectile.yPos))
+	} else {
+		projectile.xLoc += projectile.dx
+		projectile.yLoc += projectile.dy
+	}
+}
+
+// sweepPath steps from one position to another a pixel at a time along the longer axis and returns
+// the first position where blocked is true, along with the last position before it that was free.
+func sweepPath(from image.Point, to image.Point, blocked func(x, y int) bool) (image.Point, image.Point, bool) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	free := from
+	for step := 0; step <= steps; step++ {
+		point := from
+		if steps > 0 {
+			point = image.Pt(from.X+int(math.Round(float64(dx*step)/float64(steps))),
+				from.Y+int(math.Round(float64(dy*step)/float64(steps))))
+		}
+		if blocked(point.X, point.Y) {
+			return point, free, true
+		}
+		free = point
+	}
+	return to, to, false
+}
+
+func sweptBounds(projectile Sprite) image.Rectangle {
+	return hitboxAt(projectile, projectile.xPrev, projectile.yPrev).Union(hitboxBounds(projectile))
+}
+
+func placeProjectile(projectile *Sprite, location image.Point) {
+	projectile.xLoc, projectile.yLoc = location.X, location.Y
+	projectile.xPos, projectile.yPos = float64(location.X), float64(location.Y)
+}
+
+// sweepProjectileAgainstWalls checks the whole path a projectile travelled this tick, so fast shells
+// can't pass through thin walls. A shell that hits a wall is stopped where it first touched it, or
+// bounces off it if it has ricochets left.
+func (game *Game) sweepProjectileAgainstWalls(projectile *Sprite) {
+	wallAt := func(x, y int) bool {
+		probe := *projectile
+		probe.xLoc, probe.yLoc = x, y
+		return game.wallCollisionCheckCurrentLevel(probe)
+	}
+	contact, free, hit := sweepPath(image.Pt(projectile.xPrev, projectile.yPrev), image.Pt(projectile.xLoc, projectile.yLoc), wallAt)
+	if hit == false {
+		return
+	}
+	if projectile.ricochets > 0 && contact != free {
+		//flip the velocity on whichever axis ran into the wall
+		flipX, flipY := wallAt(contact.X, free.Y), wallAt(free.X, contact.Y)
+		if flipX == flipY {
+			flipX, flipY = true, true
+		}
+		if flipX {
+			projectile.dx, projectile.xVel = -projectile.dx, -projectile.xVel
+		}
+		if flipY {
+			projectile.dy, projectile.yVel = -projectile.dy, -projectile.yVel
+		}
+		projectile.ricochets -= 1
+		placeProjectile(projectile, free)
+		return
+	}
+	placeProjectile(projectile, contact)
+	projectile.wallContact = true
+}
+
+// settleWallContacts spends the shells that reached a wall this tick without hitting anything on
+// the way, damaging any breakable block they stopped against.
+func (game *Game) settleWallContacts(pool *projectilePool) {
+	for i := 0; i < len(pool.live); i++ {
+		if pool.live[i].wallContact == true && pool.live[i].collision == false {
+			game.projectileHitsBlock(pool.live[i])
+			pool.live[i].collision = true
+		}
+	}
+}
+
+// firstEnemyContact walks a projectile along the path it travelled this tick and returns the first
+// position where it overlaps a live enemy, along with the index of that enemy. The enemy that fired
+// the projectile is never hit by it.
+func (game *Game) firstEnemyContact(enemyList []Sprite, projectile Sprite) (image.Point, int, bool) {
+	candidates := game.enemyGrid.query(sweptBounds(projectile))
+	enemyIndex := -1
+	contact, _, hit := sweepPath(image.Pt(projectile.xPrev, projectile.yPrev), image.Pt(projectile.xLoc, projectile.yLoc), func(x, y int) bool {
+		probe := projectile
+		probe.xLoc, probe.yLoc = x, y
+		for _, j := range candidates {
+			if j != projectile.shooter && enemyList[j].collision == false && spritesTouch(probe, enemyList[j]) {
+				enemyIndex = j
+				return true
+			}
+		}
+		return false
+	})
+	return contact, enemyIndex, hit
+}
+
+// projectileReachesPlayer walks a projectile along the path it travelled this tick and, if it
+// touches the player anywhere on the way, moves it to that first point of contact.
+func (game *Game) projectileReachesPlayer(projectile *Sprite) bool {
+	contact, _, hit := sweepPath(image.Pt(projectile.xPrev, projectile.yPrev), image.Pt(projectile.xLoc, projectile.yLoc), func(x, y int) bool {
+		probe := *projectile
+		probe.xLoc, probe.yLoc = x, y
+		return spritesTouch(probe, game.playerSprite)
+	})
+	if hit == true {
+		placeProjectile(projectile, contact)
+	}
+	return hit
+}
+
+// projectilesCross moves two projectiles along the paths they travelled this tick together and
+// returns the point between them where they first touched.
+func projectilesCross(a Sprite, b Sprite) (image.Point, bool) {
+	steps := 0
+	for _, d := range []int{a.xLoc - a.xPrev, a.yLoc - a.yPrev, b.xLoc - b.xPrev, b.yLoc - b.yPrev} {
+		if d < 0 {
+			d = -d
+		}
+		if d > steps {
+			steps = d
+		}
+	}
+	probeA, probeB := a, b
+	for step := 0; step <= steps; step++ {
+		if steps > 0 {
+			t := float64(step) / float64(steps)
+			probeA.xLoc = a.xPrev + int(math.Round(float64(a.xLoc-a.xPrev)*t))
+			probeA.yLoc = a.yPrev + int(math.Round(float64(a.yLoc-a.yPrev)*t))
+			probeB.xLoc = b.xPrev + int(math.Round(float64(b.xLoc-b.xPrev)*t))
+			probeB.yLoc = b.yPrev + int(math.Round(float64(b.yLoc-b.yPrev)*t))
+		}
+		if spritesTouch(probeA, probeB) {
+			contact := hitboxBounds(probeA).Intersect(hitboxBounds(probeB))
+			return image.Pt((contact.Min.X+contact.Max.X)/2, (contact.Min.Y+contact.Max.Y)/2), true
+		}
+	}
+	return image.Point{}, false
+}
+
+const interceptBonus = 10
+
+// interceptProjectiles lets the player's shells shoot down enemy shells. Shells that meet destroy
+// each other unless one of them is indestructible, in which case only the other one is spent. Shooting
+// one down scores like a hit, so the bonus is multiplied by the combo but doesn't raise it.
+func (game *Game) interceptProjectiles() {
+	game.rebuildEnemyProjectileGrid()
+	for i := 0; i < len(game.playerProjectiles.live); i++ {
+		if game.playerProjectiles.live[i].collision == true {
+			continue
+		}
+		for _, j := range game.enemyProjectileGrid.query(sweptBounds(game.playerProjectiles.live[i])) {
+			playerShell, enemyShell := &game.playerProjectiles.live[i], &game.enemyProjectiles.live[j]
+			if enemyShell.collision == true || (playerShell.indestructible && enemyShell.indestructible) {
+				continue
+			}
+			contact, hit := projectilesCross(*playerShell, *enemyShell)
+			if hit == false {
+				continue
+			}
+			if enemyShell.indestructible == false {
+				enemyShell.collision = true
+				game.score += interceptBonus * game.comboMultiplier()
+			}
+			if playerShell.indestructible == false {
+				playerShell.collision = true
+			}
+			game.addImpact(contact)
+			g.enemyAndPlayerCollisionAudioPlayer.Rewind()
+			g.enemyAndPlayerCollisionAudioPlayer.Play()
+			if playerShell.collision == true {
+				break
+			}
+		}
+	}
+}
+
+const impactTicks = 12
+
+func (game *Game) addImpact(location image.Point) {
+	impact := Sprite{upPict: game.fireball.upPict, xLoc: location.X, yLoc: location.Y, health: impactTicks}
+	game.impactList = append(game.impactList, impact)
+}
+
+// manageImpacts ages the interception flashes and drops the ones that have faded out.
+func (game *Game) manageImpacts() {
+	live := game.impactList[:0]
+	for i := 0; i < len(game.impactList); i++ {
+		game.impactList[i].health -= 1
+		if game.impactList[i].health > 0 {
+			live = append(live, game.impactList[i])
+		}
+	}
+	game.impactList = live
+}
+
+const crossfireBonus = 500
+
+// recordKill remembers what killed an enemy: "player", "crossfire" or "wall". Only kills the player
+// made themselves count towards the combo, the kill stats and the kill achievements.
+func (game *Game) recordKill(anyEnemy *Sprite, source string) {
+	anyEnemy.killedBy = source
+	game.kills[source] += 1
+	if source == "player" {
+		game.killsByType[anyEnemy.enemyType] += 1
+		game.addComboKill()
+		game.achievementEvent("kill")
+	}
+}
+
+const (
+	comboWindow        = 120 //ticks, 2 seconds
+	maxComboMultiplier = 5
+)
+
+// addComboKill counts a kill towards the combo. A kill made while the combo timer is still running
+// raises the combo, otherwise a new combo starts.
+func (game *Game) addComboKill() {
+	if game.comboTicks > 0 {
+		game.combo += 1
+	} else {
+		game.combo = 1
+	}
+	game.comboTicks = comboWindow
+	if game.combo > game.maxCombo {
+		game.maxCombo = game.combo
+	}
+}
+
+// comboMultiplier is what kill and hit points are multiplied by, going up with the combo to a cap.
+func (game *Game) comboMultiplier() int {
+	if game.combo < 1 {
+		return 1
+	}
+	return int(math.Min(float64(game.combo), maxComboMultiplier))
+}
+
+// manageCombo runs down the combo timer. Each time it runs out the combo drops by one and the timer
+// starts again, so a big combo decays step by step rather than all at once.
+func (game *Game) manageCombo() {
+	if game.combo == 0 {
+		return
+	}
+	game.comboTicks -= 1
+	if game.comboTicks <= 0 {
+		game.combo -= 1
+		if game.combo > 0 {
+			game.comboTicks = comboWindow
+		}
+	}
+}
+
+// loseLife takes a life from the player and breaks their combo. cause is what killed them: "wall",
+// "shell" or "contact".
+func (game *Game) loseLife(cause string) {
+	game.deathCounter += 1
+	game.deaths[cause] += 1
+	game.levelDamage += 1
+	game.combo = 0
+	game.comboTicks = 0
+}
+
+// enemyCrossfire lets enemy shells hurt the other enemies when friendly fire is on. An enemy killed
+// by a shell that was fired at the player was baited by the player and earns the crossfire bonus.
+func (game *Game) enemyCrossfire(enemyList []Sprite) {
+	if game.friendlyFire == false {
+		return
+	}
+	for i := 0; i < len(game.enemyProjectiles.live); i++ {
+		if game.enemyProjectiles.live[i].collision == false {
+			contact, j, hit := game.firstEnemyContact(enemyList, game.enemyProjectiles.live[i])
+			if hit == true {
+				placeProjectile(&game.enemyProjectiles.live[i], contact)
+				enemyList[j].collision, game.enemyProjectiles.live[i].collision, enemyList[j].health, _ =
+					projectileCollisionWithEnemy(enemyList[j], game.enemyProjectiles.live[i])
+				if enemyList[j].collision == true {
+					game.recordKill(&enemyList[j], "crossfire")
+					if game.enemyProjectiles.live[i].baited == true {
+						game.score += crossfireBonus * game.comboMultiplier()
+						game.achievementEvent("crossfire")
+					}
+				}
+			}
+		}
+	}
+}
+
+// weaponFor picks the shell an enemy fires. Monsters throw heavy fireballs that can't be shot down.
+func (game *Game) weaponFor(anyEnemy Sprite) Sprite {
+	if anyEnemy.enemyType == "monster" {
+		return game.heavyFireball
+	}
+	return game.fireball
+}
+
+func (game *Game) playerShootFireball() {
+	mouseFire := game.mouseAim && inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft)
+	if (inpututil.IsKeyJustReleased(ebiten.KeySpace) || mouseFire) && game.playerSprite.projectileHold == false &&
+		game.playerProjectiles.hasRoom() {
 		g.playerShootsProjectileAudioPlayer.Rewind()
 		g.playerShootsProjectileAudioPlayer.Play()
 		game.playerSprite.projectileHold = true
@@ -529,193 +1495,187 @@ func (game *Game) playerShootFireball() []Sprite {
 			game.playerSprite.projectileHold = false
 		}()
 		game.projectileAndWallCollision = false
+		game.levelShotsFired += 1
+		game.shotsFired += 1
 		tempFireball := game.fireball
+		if game.ricochetShells == true {
+			tempFireball.ricochets = 1
+		}
 
-		if game.mostRecentKeyW == true {
+		if game.mouseAim == true {
+			//fireball leaves the end of the turret and travels along the turret angle
+			centerX, centerY := game.tankCenter()
+			fireballWidth, fireballHeight := tempFireball.upPict.Size()
+			tempFireball.angle = game.turretAngle
+			tempFireball.xPos = centerX + math.Cos(game.turretAngle)*38 - float64(fireballWidth)/2
+			tempFireball.yPos = centerY + math.Sin(game.turretAngle)*38 - float64(fireballHeight)/2
+			tempFireball.xVel = math.Cos(game.turretAngle) * 10
+			tempFireball.yVel = math.Sin(game.turretAngle) * 10
+			tempFireball.xLoc = int(math.Round(tempFireball.xPos))
+			tempFireball.yLoc = int(math.Round(tempFireball.yPos))
+			game.playerProjectiles.fire(tempFireball)
+		} else if game.mostRecentKeyW == true {
 			tempFireball.xLoc = game.playerSprite.xLoc + 20
 			tempFireball.yLoc = game.playerSprite.yLoc - 18
 			tempFireball.dx = 0
 			tempFireball.dy = -10
-			game.projectileList = append(game.projectileList, tempFireball)
+			game.playerProjectiles.fire(tempFireball)
 		} else if game.mostRecentKeyS == true {
 			tempFireball.xLoc = game.playerSprite.xLoc + 20
 			tempFireball.yLoc = game.playerSprite.yLoc + 55
 			tempFireball.dx = 0
 			tempFireball.dy = 10
-			game.projectileList = append(game.projectileList, tempFireball)
+			game.playerProjectiles.fire(tempFireball)
 		} else if game.mostRecentKeyA == true {
 			tempFireball.xLoc = game.playerSprite.xLoc - 15
 			tempFireball.yLoc = game.playerSprite.yLoc + 18
 			tempFireball.dx = -10
 			tempFireball.dy = 0
-			game.projectileList = append(game.projectileList, tempFireball)
+			game.playerProjectiles.fire(tempFireball)
 		} else if game.mostRecentKeyD == true {
 			tempFireball.xLoc = game.playerSprite.xLoc + 55
 			tempFireball.yLoc = game.playerSprite.yLoc + 18
 			tempFireball.dx = 10
 			tempFireball.dy = 0
-			game.projectileList = append(game.projectileList, tempFireball)
+			game.playerProjectiles.fire(tempFireball)
 		} else {
 			tempFireball.xLoc = game.playerSprite.xLoc + 20
 			tempFireball.yLoc = game.playerSprite.yLoc - 18
 			tempFireball.dx = 0
 			tempFireball.dy = -10
-			game.projectileList = append(game.projectileList, tempFireball)
-		}
-	}
-	return game.projectileList
-}
-
-func (game *Game) enemyShootFireball(i int) []Sprite {
-	if game.levelOneIsActive {
-		if game.levelOneEnemyList[i].projectileHold == false && game.levelOneEnemyList[i].collision == false {
-			game.levelOneEnemyList[i].projectileHold = true
-			g.enemyShootsProjectileAudioPlayer.Rewind()
-			g.enemyShootsProjectileAudioPlayer.Play()
-
-			go func() {
-				<-time.After(3000 * time.Millisecond)
-				game.levelOneEnemyList[i].projectileHold = false
-			}()
-			game.projectileAndWallCollision = false
-
-			tempFireball := game.fireball
-
-			if game.levelOneEnemyList[i].direction == "up" {
-				tempFireball.xLoc = game.levelOneEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelOneEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelOneEnemyList[i].enemyProjectileList = append(game.levelOneEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelOneEnemyList[i].direction == "down" {
-				tempFireball.xLoc = game.levelOneEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelOneEnemyList[i].yLoc + 55
-				tempFireball.dx = 0
-				tempFireball.dy = 3
-				game.levelOneEnemyList[i].enemyProjectileList = append(game.levelOneEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelOneEnemyList[i].direction == "left" {
-				tempFireball.xLoc = game.levelOneEnemyList[i].xLoc - 15
-				tempFireball.yLoc = game.levelOneEnemyList[i].yLoc + 18
-				tempFireball.dx = -3
-				tempFireball.dy = 0
-				game.levelOneEnemyList[i].enemyProjectileList = append(game.levelOneEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelOneEnemyList[i].direction == "right" {
-				tempFireball.xLoc = game.levelOneEnemyList[i].xLoc + 55
-				tempFireball.yLoc = game.levelOneEnemyList[i].yLoc + 18
-				tempFireball.dx = 3
-				tempFireball.dy = 0
-				game.levelOneEnemyList[i].enemyProjectileList = append(game.levelOneEnemyList[i].enemyProjectileList, tempFireball)
-			} else {
-				tempFireball.xLoc = game.levelOneEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelOneEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelOneEnemyList[i].enemyProjectileList = append(game.levelOneEnemyList[i].enemyProjectileList, tempFireball)
-			}
+			game.playerProjectiles.fire(tempFireball)
 		}
-		return game.levelOneEnemyList[i].enemyProjectileList
-
-	} else if game.levelTwoIsActive {
-		if game.levelTwoEnemyList[i].projectileHold == false && game.levelTwoEnemyList[i].collision == false {
-			game.levelTwoEnemyList[i].projectileHold = true
-			g.enemyShootsProjectileAudioPlayer.Rewind()
-			g.enemyShootsProjectileAudioPlayer.Play()
-
-			go func() {
-				<-time.After(3000 * time.Millisecond)
-				game.levelTwoEnemyList[i].projectileHold = false
-			}()
-			game.projectileAndWallCollision = false
-
-			tempFireball := game.fireball
-
-			if game.levelTwoEnemyList[i].direction == "up" {
-				tempFireball.xLoc = game.levelTwoEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelTwoEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelTwoEnemyList[i].enemyProjectileList = append(game.levelTwoEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelTwoEnemyList[i].direction == "down" {
-				tempFireball.xLoc = game.levelTwoEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelTwoEnemyList[i].yLoc + 55
-				tempFireball.dx = 0
-				tempFireball.dy = 3
-				game.levelTwoEnemyList[i].enemyProjectileList = append(game.levelTwoEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelTwoEnemyList[i].direction == "left" {
-				tempFireball.xLoc = game.levelTwoEnemyList[i].xLoc - 15
-				tempFireball.yLoc = game.levelTwoEnemyList[i].yLoc + 18
-				tempFireball.dx = -3
-				tempFireball.dy = 0
-				game.levelTwoEnemyList[i].enemyProjectileList = append(game.levelTwoEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelTwoEnemyList[i].direction == "right" {
-				tempFireball.xLoc = game.levelTwoEnemyList[i].xLoc + 55
-				tempFireball.yLoc = game.levelTwoEnemyList[i].yLoc + 18
-				tempFireball.dx = 3
-				tempFireball.dy = 0
-				game.levelTwoEnemyList[i].enemyProjectileList = append(game.levelTwoEnemyList[i].enemyProjectileList, tempFireball)
-			} else {
-				tempFireball.xLoc = game.levelTwoEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelTwoEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelTwoEnemyList[i].enemyProjectileList = append(game.levelTwoEnemyList[i].enemyProjectileList, tempFireball)
-			}
+	}
+}
+
+func (game *Game) enemyShootFireball(i int) {
+	enemyList := game.activeEnemyList()
+	if enemyList[i].projectileHold == false && enemyList[i].collision == false &&
+		game.enemyProjectiles.hasRoom() {
+		enemyList[i].projectileHold = true
+		g.enemyShootsProjectileAudioPlayer.Rewind()
+		g.enemyShootsProjectileAudioPlayer.Play()
+
+		go func() {
+			<-time.After(3000 * time.Millisecond)
+			enemyList[i].projectileHold = false
+		}()
+		game.projectileAndWallCollision = false
+
+		tempFireball := game.weaponFor(enemyList[i])
+		tempFireball.shooter = i
+
+		if enemyList[i].direction == "up" {
+			tempFireball.xLoc = enemyList[i].xLoc + 20
+			tempFireball.yLoc = enemyList[i].yLoc - 18
+			tempFireball.dx = 0
+			tempFireball.dy = -3
+		} else if enemyList[i].direction == "down" {
+			tempFireball.xLoc = enemyList[i].xLoc + 20
+			tempFireball.yLoc = enemyList[i].yLoc + 55
+			tempFireball.dx = 0
+			tempFireball.dy = 3
+		} else if enemyList[i].direction == "left" {
+			tempFireball.xLoc = enemyList[i].xLoc - 15
+			tempFireball.yLoc = enemyList[i].yLoc + 18
+			tempFireball.dx = -3
+			tempFireball.dy = 0
+		} else if enemyList[i].direction == "right" {
+			tempFireball.xLoc = enemyList[i].xLoc + 55
+			tempFireball.yLoc = enemyList[i].yLoc + 18
+			tempFireball.dx = 3
+			tempFireball.dy = 0
+		} else {
+			tempFireball.xLoc = enemyList[i].xLoc + 20
+			tempFireball.yLoc = enemyList[i].yLoc - 18
+			tempFireball.dx = 0
+			tempFireball.dy = -3
 		}
-		return game.levelTwoEnemyList[i].enemyProjectileList
+		//a shell fired at the player is one they baited, should it hit another enemy
+		tempFireball.baited = inLineOfFire(tempFireball, hitboxBounds(game.playerSprite))
+		game.enemyProjectiles.fire(tempFireball)
+	}
+}
 
-	} else if game.levelThreeIsActive {
-		if game.levelThreeEnemyList[i].projectileHold == false && game.levelThreeEnemyList[i].collision == false {
-			game.levelThreeEnemyList[i].projectileHold = true
-			g.enemyShootsProjectileAudioPlayer.Rewind()
-			g.enemyShootsProjectileAudioPlayer.Play()
-
-			go func() {
-				<-time.After(3000 * time.Millisecond)
-				game.levelThreeEnemyList[i].projectileHold = false
-			}()
-			game.projectileAndWallCollision = false
-
-			tempFireball := game.fireball
-
-			if game.levelThreeEnemyList[i].direction == "up" {
-				tempFireball.xLoc = game.levelThreeEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelThreeEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelThreeEnemyList[i].enemyProjectileList = append(game.levelThreeEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelThreeEnemyList[i].direction == "down" {
-				tempFireball.xLoc = game.levelThreeEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelThreeEnemyList[i].yLoc + 55
-				tempFireball.dx = 0
-				tempFireball.dy = 3
-				game.levelThreeEnemyList[i].enemyProjectileList = append(game.levelThreeEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelThreeEnemyList[i].direction == "left" {
-				tempFireball.xLoc = game.levelThreeEnemyList[i].xLoc - 15
-				tempFireball.yLoc = game.levelThreeEnemyList[i].yLoc + 18
-				tempFireball.dx = -3
-				tempFireball.dy = 0
-				game.levelThreeEnemyList[i].enemyProjectileList = append(game.levelThreeEnemyList[i].enemyProjectileList, tempFireball)
-			} else if game.levelThreeEnemyList[i].direction == "right" {
-				tempFireball.xLoc = game.levelThreeEnemyList[i].xLoc + 55
-				tempFireball.yLoc = game.levelThreeEnemyList[i].yLoc + 18
-				tempFireball.dx = 3
-				tempFireball.dy = 0
-				game.levelThreeEnemyList[i].enemyProjectileList = append(game.levelThreeEnemyList[i].enemyProjectileList, tempFireball)
-			} else {
-				tempFireball.xLoc = game.levelThreeEnemyList[i].xLoc + 20
-				tempFireball.yLoc = game.levelThreeEnemyList[i].yLoc - 18
-				tempFireball.dx = 0
-				tempFireball.dy = -3
-				game.levelThreeEnemyList[i].enemyProjectileList = append(game.levelThreeEnemyList[i].enemyProjectileList, tempFireball)
-			}
+// baitMargin is how far to the side of a shell's path the player can be and still count as the
+// one it was fired at.
+const baitMargin = 40
+
+// inLineOfFire reports whether target is ahead of a shell along the direction it is travelling, within
+// baitMargin of its path.
+func inLineOfFire(shell Sprite, target image.Rectangle) bool {
+	lane := hitboxBounds(shell).Inset(-baitMargin)
+	if shell.dx > 0 {
+		lane.Max.X = ScreenWidth
+	} else if shell.dx < 0 {
+		lane.Min.X = 0
+	}
+	if shell.dy > 0 {
+		lane.Max.Y = ScreenHeight
+	} else if shell.dy < 0 {
+		lane.Min.Y = 0
+	}
+	return lane.Overlaps(target)
+}
+
+// Tank controls tuning, per tick.
+const (
+	tankTurnSpeed    = 0.06 //radians
+	tankAcceleration = 0.15
+	tankFriction     = 0.08
+	tankTopSpeed     = 3.0
+	tankReverseSpeed = 1.5
+)
+
+func (game *Game) driveTank() {
+	//player was moved by a respawn or level change since the last tick
+	if int(math.Round(game.playerSprite.xPos)) != game.playerSprite.xLoc ||
+		int(math.Round(game.playerSprite.yPos)) != game.playerSprite.yLoc {
+		game.playerSprite.xPos = float64(game.playerSprite.xLoc)
+		game.playerSprite.yPos = float64(game.playerSprite.yLoc)
+		game.playerSprite.speed = 0
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		game.playerSprite.angle -= tankTurnSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		game.playerSprite.angle += tankTurnSpeed
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		game.playerSprite.speed = math.Min(game.playerSprite.speed+tankAcceleration, tankTopSpeed)
+	} else if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		game.playerSprite.speed = math.Max(game.playerSprite.speed-tankAcceleration, -tankReverseSpeed)
+	} else if game.playerSprite.speed > 0 {
+		game.playerSprite.speed = math.Max(game.playerSprite.speed-tankFriction, 0)
+	} else if game.playerSprite.speed < 0 {
+		game.playerSprite.speed = math.Min(game.playerSprite.speed+tankFriction, 0)
+	}
+
+	oldX, oldY := game.playerSprite.xLoc, game.playerSprite.yLoc
+	game.playerSprite.xPos += math.Cos(game.playerSprite.angle) * game.playerSprite.speed
+	game.playerSprite.yPos += math.Sin(game.playerSprite.angle) * game.playerSprite.speed
+	game.playerSprite.xLoc = int(math.Round(game.playerSprite.xPos))
+	game.playerSprite.yLoc = int(math.Round(game.playerSprite.yPos))
+	if game.wallsSolid() == true {
+		game.slideAgainstWalls(&game.playerSprite, oldX, oldY)
+		if game.playerSprite.xLoc == oldX {
+			game.playerSprite.xPos = float64(oldX)
+		}
+		if game.playerSprite.yLoc == oldY {
+			game.playerSprite.yPos = float64(oldY)
 		}
-		return game.levelThreeEnemyList[i].enemyProjectileList
-	} else {
-		return game.levelOneEnemyList
 	}
 }
 
 func (game *Game) changeTankDirection() {
+	if game.tankControls == true {
+		//the hull turns freely, so its hits use the bounding box instead of a facing's mask
+		game.playerSprite.direction = "rotated"
+		game.driveTank()
+		return
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
 		game.playerSprite.dx = -3
 		game.mostRecentKeyLeft = true
@@ -747,12 +1707,30 @@ func (game *Game) changeTankDirection() {
 	} else if inpututil.IsKeyJustReleased(ebiten.KeyUp) || inpututil.IsKeyJustReleased(ebiten.KeyDown) {
 		game.playerSprite.dy = 0
 	}
+	//the facing picks the same mask as the tank picture being drawn
+	if game.mostRecentKeyLeft == true {
+		game.playerSprite.direction = "left"
+	} else if game.mostRecentKeyRight == true {
+		game.playerSprite.direction = "right"
+	} else if game.mostRecentKeyDown == true {
+		game.playerSprite.direction = "down"
+	} else {
+		game.playerSprite.direction = "up"
+	}
+	oldX, oldY := game.playerSprite.xLoc, game.playerSprite.yLoc
 	game.playerSprite.yLoc += game.playerSprite.dy
 	game.playerSprite.xLoc += game.playerSprite.dx
+	if game.wallsSolid() == true {
+		game.slideAgainstWalls(&game.playerSprite, oldX, oldY)
+	}
 }
 
 func (game *Game) changeTankTopperDirection() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if game.mouseAim == true {
+		cursorX, cursorY := ebiten.CursorPosition()
+		centerX, centerY := game.tankCenter()
+		game.turretAngle = math.Atan2(float64(cursorY)-centerY, float64(cursorX)-centerX)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyW) {
 		game.mostRecentKeyA = false
 		game.mostRecentKeyS = false
 		game.mostRecentKeyD = false
@@ -795,6 +1773,7 @@ func (game *Game) manageTankTopperOffset() {
 
 func (game *Game) spawnLevel1Enemies() {
 	if game.spawnedLevel1Enemies == false {
+		game.enemyProjectiles.clear()
 		personEnemy1 := game.personEnemy
 		personEnemy2 := game.personEnemy
 		monsterEnemy1 := game.monsterEnemy
@@ -820,16 +1799,17 @@ func (game *Game) spawnLevel1Enemies() {
 		game.levelOneEnemyList = append(game.levelOneEnemyList, personEnemy2)
 		game.levelOneEnemyList = append(game.levelOneEnemyList, monsterEnemy1)
 		game.levelOneEnemyList = append(game.levelOneEnemyList, monsterEnemy2)
+
+		//breakable barrier sealing the corridor on the right side of the map
+		game.placeBlocks([]image.Point{{625, 325}, {675, 325}, {725, 325}})
+		game.spawnCollectibles()
 	}
 	game.spawnedLevel1Enemies = true
 }
 
 func (game *Game) spawnLevel2Enemies() {
 	if game.spawnedLevel2Enemies == false {
-		game.collectedGold = false
-		rand.Seed(int64(time.Now().Second()))
-		game.coinSprite.xLoc = rand.Intn(ScreenWidth - 72)
-		game.coinSprite.yLoc = rand.Intn(ScreenHeight - 72)
+		game.enemyProjectiles.clear()
 		personEnemy1 := game.personEnemy
 		personEnemy2 := game.personEnemy
 		monsterEnemy1 := game.monsterEnemy
@@ -855,18 +1835,17 @@ func (game *Game) spawnLevel2Enemies() {
 		game.levelTwoEnemyList = append(game.levelTwoEnemyList, personEnemy2)
 		game.levelTwoEnemyList = append(game.levelTwoEnemyList, monsterEnemy1)
 		game.levelTwoEnemyList = append(game.levelTwoEnemyList, monsterEnemy2)
+
+		//breakable barrier across the middle corridor
+		game.placeBlocks([]image.Point{{325, 300}, {375, 300}, {425, 300}, {475, 300}})
+		game.spawnCollectibles()
 	}
 	game.spawnedLevel2Enemies = true
 }
 
 func (game *Game) spawnLevel3Enemies() {
 	if game.spawnedLevel3Enemies == false {
-		if game.extraLifeAwarded == false {
-			game.collectedGold = false
-			rand.Seed(int64(time.Now().Second()))
-			game.coinSprite.xLoc = rand.Intn(ScreenWidth - 72)
-			game.coinSprite.yLoc = rand.Intn(ScreenHeight - 72)
-		}
+		game.enemyProjectiles.clear()
 		personEnemy1 := game.personEnemy
 		personEnemy2 := game.personEnemy
 		monsterEnemy1 := game.monsterEnemy
@@ -892,10 +1871,114 @@ func (game *Game) spawnLevel3Enemies() {
 		game.levelThreeEnemyList = append(game.levelThreeEnemyList, personEnemy2)
 		game.levelThreeEnemyList = append(game.levelThreeEnemyList, monsterEnemy1)
 		game.levelThreeEnemyList = append(game.levelThreeEnemyList, monsterEnemy2)
+
+		//breakable barrier across the corridor right of the bottom wall
+		game.placeBlocks([]image.Point{{625, 450}, {675, 450}, {725, 450}})
+		game.spawnCollectibles()
 	}
 	game.spawnedLevel3Enemies = true
 }
 
+func (game *Game) spawnGeneratedRoomEnemies() {
+	if game.spawnedGeneratedRoom == false {
+		game.enemyProjectiles.clear()
+		game.placeBlocks(nil)
+		game.generatedEnemyList = nil
+		for i := 0; i < len(game.generatedLayout.enemySpots); i++ {
+			enemy := game.personEnemy
+			enemy.health = 1
+			if i%2 == 1 {
+				enemy = game.monsterEnemy
+				enemy.health = 2
+			}
+			//enemies patrol back and forth across their cell, alternating between the two axes
+			if i%4 < 2 {
+				enemy.dx, enemy.direction = 1, "right"
+			} else {
+				enemy.dy, enemy.direction = 1, "down"
+			}
+			enemyBox := hitboxAt(enemy, 0, 0)
+			spot := game.generatedLayout.enemySpots[i]
+			enemy.xLoc = spot.X - (enemyBox.Min.X+enemyBox.Max.X)/2
+			enemy.yLoc = spot.Y - (enemyBox.Min.Y+enemyBox.Max.Y)/2
+			game.generatedEnemyList = append(game.generatedEnemyList, enemy)
+		}
+		game.spawnCollectibles()
+	}
+	game.spawnedGeneratedRoom = true
+}
+
+// movementGeneratedRoomEnemies moves the enemies of a generated room. Like in the hand-made levels,
+// enemies close to the player chase and shoot at them. The others patrol, turning around before
+// they would walk into a wall.
+func (game *Game) movementGeneratedRoomEnemies() {
+	for i := 0; i < len(game.generatedEnemyList); i++ {
+		enemy := &game.generatedEnemyList[i]
+		if enemy.collision == true {
+			continue
+		}
+		xGap := game.playerSprite.xLoc - enemy.xLoc
+		yGap := game.playerSprite.yLoc - enemy.yLoc
+		if math.Abs(float64(xGap)) < 150 && math.Abs(float64(yGap)) < 150 {
+			enemy.inPlayerProximity = true
+			enemy.dx, enemy.dy = 1, 1
+			if xGap < 0 {
+				enemy.dx = -1
+			}
+			if yGap < 0 {
+				enemy.dy = -1
+			}
+			if math.Abs(float64(xGap)) > math.Abs(float64(yGap)) && enemy.dx > 0 {
+				enemy.direction = "right"
+			} else if math.Abs(float64(xGap)) > math.Abs(float64(yGap)) {
+				enemy.direction = "left"
+			} else if enemy.dy > 0 {
+				enemy.direction = "down"
+			} else {
+				enemy.direction = "up"
+			}
+			enemy.xLoc += enemy.dx
+			enemy.yLoc += enemy.dy
+			game.enemyShootFireball(i)
+			continue
+		}
+		if enemy.inPlayerProximity == true {
+			//the player got away, go back to patrolling along one axis
+			enemy.inPlayerProximity = false
+			if enemy.direction == "left" || enemy.direction == "right" {
+				enemy.dy = 0
+			} else {
+				enemy.dx = 0
+			}
+		}
+		probe := *enemy
+		probe.xLoc += enemy.dx * 4
+		probe.yLoc += enemy.dy * 4
+		if game.wallCollisionCheckCurrentLevel(probe) {
+			enemy.dx, enemy.dy = -enemy.dx, -enemy.dy
+		}
+		if enemy.dx > 0 {
+			enemy.direction = "right"
+		} else if enemy.dx < 0 {
+			enemy.direction = "left"
+		} else if enemy.dy > 0 {
+			enemy.direction = "down"
+		} else {
+			enemy.direction = "up"
+		}
+		enemy.xLoc += enemy.dx
+		enemy.yLoc += enemy.dy
+	}
+}
+
+func (game *Game) manageGeneratedRoomCollisionDetection() {
+	entryPoint := image.Pt(game.playerSprite.xLoc, game.playerSprite.yLoc)
+	if game.spawnedGeneratedRoom == true {
+		entryPoint = mazeCellCenter(doorCell(game.entrance)).Sub(image.Pt(30, 30))
+	}
+	game.manageCollisionDetection(game.generatedEnemyList, entryPoint, entryPoint)
+}
+
 func (game *Game) movementLevel1Enemies() {
 	personEnemyMovementSpeed := 1
 	if len(game.levelOneEnemyList) == 4 {
@@ -917,8 +2000,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 
 				} else if game.levelOneEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc <= game.playerSprite.yLoc {
@@ -933,8 +2015,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelOneEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy to the left and below player
@@ -948,8 +2029,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelOneEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy location to the right and below
@@ -963,8 +2043,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			} else if math.Abs(float64(game.levelOneEnemyList[i].xLoc-game.playerSprite.xLoc)) >= 150 ||
 				math.Abs(float64(game.levelOneEnemyList[i].yLoc-game.playerSprite.yLoc)) >= 150 &&
@@ -1069,8 +2148,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelOneEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc <= game.playerSprite.yLoc {
 					game.levelOneEnemyList[i].dx = -1
@@ -1083,8 +2161,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelOneEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelOneEnemyList[i].dx = 1
@@ -1097,8 +2174,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelOneEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelOneEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelOneEnemyList[i].dx = -1
@@ -1111,8 +2187,7 @@ func (game *Game) movementLevel1Enemies() {
 					}
 					game.levelOneEnemyList[i].xLoc += game.levelOneEnemyList[i].dx
 					game.levelOneEnemyList[i].yLoc += game.levelOneEnemyList[i].dy
-					game.levelOneEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			}
 		}
@@ -1140,8 +2215,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 
 				} else if game.levelTwoEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc <= game.playerSprite.yLoc {
@@ -1156,8 +2230,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelTwoEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy to the left and below player
@@ -1171,8 +2244,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelTwoEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy location to the right and below
@@ -1186,8 +2258,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			} else if math.Abs(float64(game.levelTwoEnemyList[i].xLoc-game.playerSprite.xLoc)) >= 150 ||
 				math.Abs(float64(game.levelTwoEnemyList[i].yLoc-game.playerSprite.yLoc)) >= 150 &&
@@ -1276,8 +2347,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelTwoEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc <= game.playerSprite.yLoc {
 					game.levelTwoEnemyList[i].dx = -1
@@ -1290,8 +2360,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelTwoEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelTwoEnemyList[i].dx = 1
@@ -1304,8 +2373,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelTwoEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelTwoEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelTwoEnemyList[i].dx = -1
@@ -1318,8 +2386,7 @@ func (game *Game) movementLevel2Enemies() {
 					}
 					game.levelTwoEnemyList[i].xLoc += game.levelTwoEnemyList[i].dx
 					game.levelTwoEnemyList[i].yLoc += game.levelTwoEnemyList[i].dy
-					game.levelTwoEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			}
 		}
@@ -1347,8 +2414,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 
 				} else if game.levelThreeEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc <= game.playerSprite.yLoc {
@@ -1363,8 +2429,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelThreeEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy to the left and below player
@@ -1378,8 +2443,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelThreeEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					//enemy location to the right and below
@@ -1393,8 +2457,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			} else if math.Abs(float64(game.levelThreeEnemyList[i].xLoc-game.playerSprite.xLoc)) >= 150 ||
 				math.Abs(float64(game.levelThreeEnemyList[i].yLoc-game.playerSprite.yLoc)) >= 150 &&
@@ -1482,8 +2545,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelThreeEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc <= game.playerSprite.yLoc {
 					game.levelThreeEnemyList[i].dx = -1
@@ -1496,8 +2558,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelThreeEnemyList[i].xLoc <= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelThreeEnemyList[i].dx = 1
@@ -1510,8 +2571,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				} else if game.levelThreeEnemyList[i].xLoc >= game.playerSprite.xLoc &&
 					game.levelThreeEnemyList[i].yLoc >= game.playerSprite.yLoc {
 					game.levelThreeEnemyList[i].dx = -1
@@ -1524,8 +2584,7 @@ func (game *Game) movementLevel3Enemies() {
 					}
 					game.levelThreeEnemyList[i].xLoc += game.levelThreeEnemyList[i].dx
 					game.levelThreeEnemyList[i].yLoc += game.levelThreeEnemyList[i].dy
-					game.levelThreeEnemyList[i].enemyProjectileList =
-						game.enemyShootFireball(i)
+					game.enemyShootFireball(i)
 				}
 			}
 		}
@@ -1533,524 +2592,949 @@ func (game *Game) movementLevel3Enemies() {
 }
 
 func (game *Game) manageLevel1CollisionDetection() {
-	if game.collectedGold == false {
-		game.collectedGold = game.gotGold(game.playerSprite, game.coinSprite)
+	wallRespawn := image.Pt(74, ScreenHeight/2) //player width
+	if game.playerSprite.xLoc < ScreenWidth/2 {
+		wallRespawn = image.Pt(650, 450)
 	}
+	game.manageCollisionDetection(game.levelOneEnemyList, image.Pt(190, ScreenHeight*0.72), wallRespawn)
+}
+
+// manageCollisionDetection runs every collision check of the current room. A player killed by an
+// enemy or its fire comes back at respawn, and one killed by a lethal wall comes back at wallRespawn.
+func (game *Game) manageCollisionDetection(enemyList []Sprite, respawn image.Point, wallRespawn image.Point) {
+	game.collectItems()
 
 	//player collision with wall check
-	if game.playerAndWallCollision == false {
-		game.playerAndWallCollision = wallCollisionCheckFirstLevel(game.playerSprite, 61)
-	} else {
-		if game.playerSprite.xLoc < ScreenWidth/2 {
-			game.playerSprite.yLoc = 450
-			game.playerSprite.xLoc = 650 //player width
-		} else if game.playerSprite.xLoc > ScreenWidth/2 {
-			game.playerSprite.yLoc = ScreenHeight / 2
-			game.playerSprite.xLoc = 74 //player width
-		}
+	if game.playerAndWallCollision == false && game.wallsSolid() == false {
+		game.playerAndWallCollision = game.wallCollisionCheckCurrentLevel(game.playerSprite)
+	} else if game.playerAndWallCollision == true {
+		game.playerSprite.xLoc, game.playerSprite.yLoc = wallRespawn.X, wallRespawn.Y
 		game.playerAndWallCollision = false
-		game.deathCounter += 1
+		game.loseLife("wall")
 		g.playerDeathAudioPlayer.Rewind()
 		g.playerDeathAudioPlayer.Play()
 	}
 
-	//enemy collision with wall check
-	if len(game.levelOneEnemyList) > 0 {
-		for i := 0; i < len(game.levelOneEnemyList); i++ {
-			if game.levelOneEnemyList[i].collision == false {
-				if game.levelOneEnemyList[i].direction == "left" {
-					spriteWidth, _ := game.levelOneEnemyList[i].leftPict.Size()
-					game.levelOneEnemyList[i].collision = wallCollisionCheckFirstLevel(game.levelOneEnemyList[i], spriteWidth)
-					if game.levelOneEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelOneEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelOneEnemyList[i].direction == "right" {
-					spriteWidth, _ := game.levelOneEnemyList[i].rightPict.Size()
-					game.levelOneEnemyList[i].collision = wallCollisionCheckFirstLevel(game.levelOneEnemyList[i], spriteWidth)
-					if game.levelOneEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelOneEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelOneEnemyList[i].direction == "up" {
-					spriteWidth, _ := game.levelOneEnemyList[i].upPict.Size()
-					game.levelOneEnemyList[i].collision = wallCollisionCheckFirstLevel(game.levelOneEnemyList[i], spriteWidth)
-					if game.levelOneEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelOneEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelOneEnemyList[i].direction == "down" {
-					spriteWidth, _ := game.levelOneEnemyList[i].downPict.Size()
-					game.levelOneEnemyList[i].collision = wallCollisionCheckFirstLevel(game.levelOneEnemyList[i], spriteWidth)
-					if game.levelOneEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelOneEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				}
-			} else {
-				if game.levelOneEnemyList[i].health == 2 && game.levelOneEnemyList[i].collision == true {
-					game.score += 300
-					game.levelOneEnemyList[i].health = 0
-				}
-				if game.levelOneEnemyList[i].health == 1 && game.levelOneEnemyList[i].collision == true {
-					game.score += 200
-					game.levelOneEnemyList[i].health = 0
-				}
-				game.levelOneEnemyList[i].dx = 0
-				game.levelOneEnemyList[i].dy = 0
-			}
+	//enemy collision with wall check
+	if len(enemyList) > 0 {
+		for i := 0; i < len(enemyList); i++ {
+			if enemyList[i].collision == false {
+				enemyList[i].collision = game.wallCollisionCheckCurrentLevel(enemyList[i])
+				if enemyList[i].collision == true {
+					game.recordKill(&enemyList[i], "wall")
+				}
+				if enemyList[i].collision == true && enemyList[i].enemyType == "monster" {
+					g.monsterEnemyDeathAudioPlayer.Rewind()
+					g.monsterEnemyDeathAudioPlayer.Play()
+				} else if enemyList[i].collision == true {
+					g.humanEnemyDeathAudioPlayer.Rewind()
+					g.humanEnemyDeathAudioPlayer.Play()
+				}
+			} else {
+				enemyList[i].health = 0
+				enemyList[i].dx = 0
+				enemyList[i].dy = 0
+			}
+		}
+	}
+
+	//player projectile collides with wall check
+	if len(game.playerProjectiles.live) > 0 {
+		for i := 0; i < len(game.playerProjectiles.live); i++ {
+			if game.playerProjectiles.live[i].collision == false {
+				moveProjectile(&game.playerProjectiles.live[i])
+				game.sweepProjectileAgainstWalls(&game.playerProjectiles.live[i])
+			}
+		}
+	}
+
+	//enemy projectile collides with wall check
+	for i := 0; i < len(game.enemyProjectiles.live); i++ {
+		if game.enemyProjectiles.live[i].collision == false {
+			moveProjectile(&game.enemyProjectiles.live[i])
+			game.sweepProjectileAgainstWalls(&game.enemyProjectiles.live[i])
+		}
+	}
+
+	//player projectile collides with enemy projectile check
+	game.interceptProjectiles()
+
+	//player collides with enemy check
+	if len(enemyList) > 0 {
+		for i := 0; i < len(enemyList); i++ {
+			if enemyList[i].collision == false {
+				death := playerCollisionWithEnemy(enemyList[i], game.playerSprite)
+				if death == 1 {
+					g.enemyAndPlayerCollisionAudioPlayer.Rewind()
+					g.enemyAndPlayerCollisionAudioPlayer.Play()
+					game.playerSprite.xLoc, game.playerSprite.yLoc = respawn.X, respawn.Y
+					game.loseLife("contact")
+				}
+			}
+		}
+	}
+
+	//enemy projectile collides with player check
+	game.rebuildEnemyProjectileGrid()
+	for _, i := range game.enemyProjectileGrid.query(hitboxBounds(game.playerSprite)) {
+		if game.enemyProjectiles.live[i].collision == false && game.projectileReachesPlayer(&game.enemyProjectiles.live[i]) {
+			death := 0
+			game.enemyProjectiles.live[i].collision, death =
+				projectileCollisionWithPlayer(game.playerSprite,
+					game.enemyProjectiles.live[i])
+			if death == 1 {
+				g.playerDeathAudioPlayer.Rewind()
+				g.playerDeathAudioPlayer.Play()
+				game.playerSprite.xLoc, game.playerSprite.yLoc = respawn.X, respawn.Y
+				game.loseLife("shell")
+			}
+		}
+	}
+
+	//player projectile collides with enemy check
+	game.rebuildEnemyGrid(enemyList)
+	for i := 0; i < len(game.playerProjectiles.live); i++ {
+		if game.playerProjectiles.live[i].collision == false {
+			contact, j, hit := game.firstEnemyContact(enemyList, game.playerProjectiles.live[i])
+			if hit == true {
+				placeProjectile(&game.playerProjectiles.live[i], contact)
+				game.levelShotsHit += 1
+				game.shotsHit += 1
+				additionalScore := 0
+				enemyList[j].collision, game.playerProjectiles.live[i].collision, enemyList[j].health, additionalScore =
+					projectileCollisionWithEnemy(enemyList[j], game.playerProjectiles.live[i])
+				if enemyList[j].collision == true {
+					game.recordKill(&enemyList[j], "player")
+				}
+				game.score += additionalScore * game.comboMultiplier()
+			}
+		}
+	}
+
+	//enemy projectile collides with other enemy check
+	game.enemyCrossfire(enemyList)
+}
+
+func (game *Game) manageLevel2CollisionDetection() {
+	game.manageCollisionDetection(game.levelTwoEnemyList, image.Pt(100, 100), image.Pt(100, 100))
+}
+
+func (game *Game) manageLevel3CollisionDetection() {
+	game.manageCollisionDetection(game.levelThreeEnemyList, image.Pt(600, 100), image.Pt(600, 100))
+}
+
+// levelObjective is one condition a level needs met before the player moves on. kind is "clear"
+// (destroy every enemy), "exit" (reach the exit area), "survive" (stay alive for surviveTicks) or
+// "collect" (pick up itemCount items on the level).
+type levelObjective struct {
+	kind         string
+	exit         image.Rectangle
+	surviveTicks int
+	itemCount    int
+}
+
+// roomDoor is a doorway in the outer wall of a room. side is "north", "south", "east" or "west",
+// from and to give the span of the opening along that wall, and leadsTo is the room it opens onto.
+type roomDoor struct {
+	side    string
+	from    int
+	to      int
+	leadsTo int
+}
+
+// room is one screen of the maze. All of a room's objectives must be met at the same time before
+// its doors open. A room without doors is the last one, and completing it wins the game. Walls of a
+// room with solidWalls block movement instead of costing a life.
+type room struct {
+	walls      []image.Rectangle
+	objectives []levelObjective
+	doors      []roomDoor
+	solidWalls bool
+}
+
+// rooms is the room graph of the hand-made maze, indexed by the level drawn in each room. Leaving a
+// room through a door enters the room it leads to through the opposite wall, at the same place.
+var rooms = []room{
+	{
+		walls:      levelOneWalls,
+		objectives: []levelObjective{{kind: "clear"}},
+		doors:      []roomDoor{{side: "east", from: 450, to: 560, leadsTo: 1}, {side: "south", from: 650, to: 760, leadsTo: 2}},
+	},
+	{
+		walls:      levelTwoWalls,
+		objectives: []levelObjective{{kind: "survive", surviveTicks: 45 * 60}}, //ticks, 45 seconds
+		doors:      []roomDoor{{side: "south", from: 650, to: 760, leadsTo: 2}},
+	},
+	{
+		walls: levelThreeWalls,
+		//the exit is in the bottom right corner, behind the breakable barrier or round the far side
+		objectives: []levelObjective{{kind: "collect", itemCount: 3}, {kind: "exit", exit: image.Rect(650, 560, 760, 660)}},
+	},
+}
+
+func oppositeSide(side string) string {
+	if side == "north" {
+		return "south"
+	} else if side == "south" {
+		return "north"
+	} else if side == "east" {
+		return "west"
+	}
+	return "east"
+}
+
+// opening returns the part of the outer wall a door cuts away.
+func (door roomDoor) opening() image.Rectangle {
+	boundaryWidth := 25
+	if door.side == "north" {
+		return image.Rect(door.from, 0, door.to, boundaryWidth)
+	} else if door.side == "south" {
+		return image.Rect(door.from, ScreenHeight-boundaryWidth, door.to, ScreenHeight)
+	} else if door.side == "west" {
+		return image.Rect(0, door.from, boundaryWidth, door.to)
+	}
+	return image.Rect(ScreenWidth-boundaryWidth, door.from, ScreenWidth, door.to)
+}
+
+// endlessDoor leads from the last hand-made room into the generated rooms in endless mode. Its span
+// lines up with a row of the generated maze grid.
+var endlessDoor = roomDoor{side: "east", from: 560, to: 660, leadsTo: generatedRoomIndex}
+
+// roomDoors returns every door of the current room, open or not.
+func (game *Game) roomDoors() []roomDoor {
+	doors := game.currentRoom().doors
+	if game.endlessMode == true && len(doors) == 0 && game.currentLevel() != generatedRoomIndex {
+		return []roomDoor{endlessDoor}
+	}
+	return doors
+}
+
+// openDoors returns the doors of the current room the player may leave through. Doors stay shut
+// until the room is complete, and the door the player came in by is sealed behind them.
+func (game *Game) openDoors() []roomDoor {
+	if game.levelComplete() == false {
+		return nil
+	}
+	var open []roomDoor
+	doors := game.roomDoors()
+	for i := 0; i < len(doors); i++ {
+		if doors[i].side == game.entrance.side && doors[i].opening().Overlaps(game.entrance.opening()) {
+			continue
 		}
+		open = append(open, doors[i])
 	}
+	return open
+}
 
-	//player projectile collides with wall check
-	if len(game.projectileList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			if game.projectileList[i].collision == false {
-				game.projectileList[i].xLoc += game.projectileList[i].dx
-				game.projectileList[i].yLoc += game.projectileList[i].dy
-				game.projectileList[i].collision = wallCollisionCheckFirstLevel(game.projectileList[i], 20)
+// inOpenDoorway reports whether a box that reaches into the outer wall does so only through an open
+// doorway.
+func (game *Game) inOpenDoorway(box image.Rectangle) bool {
+	boundaryWidth := 25
+	doors := game.openDoors()
+	for i := 0; i < len(doors); i++ {
+		if doors[i].side == "north" || doors[i].side == "south" {
+			if box.Min.X >= doors[i].from && box.Max.X <= doors[i].to &&
+				(doors[i].side == "south" || box.Max.Y <= ScreenHeight-boundaryWidth) &&
+				(doors[i].side == "north" || box.Min.Y >= boundaryWidth) {
+				return true
 			}
+		} else if box.Min.Y >= doors[i].from && box.Max.Y <= doors[i].to &&
+			(doors[i].side == "east" || box.Max.X <= ScreenWidth-boundaryWidth) &&
+			(doors[i].side == "west" || box.Min.X >= boundaryWidth) {
+			return true
 		}
 	}
+	return false
+}
 
-	//enemy projectile collides with wall check
-	if len(game.levelOneEnemyList) > 0 {
-		for i := 0; i < len(game.levelOneEnemyList); i++ {
-			if len(game.levelOneEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelOneEnemyList[i].enemyProjectileList); j++ {
-					if game.levelOneEnemyList[i].enemyProjectileList[j].collision == false {
-						game.levelOneEnemyList[i].enemyProjectileList[j].xLoc += game.levelOneEnemyList[i].enemyProjectileList[j].dx
-						game.levelOneEnemyList[i].enemyProjectileList[j].yLoc += game.levelOneEnemyList[i].enemyProjectileList[j].dy
-						game.levelOneEnemyList[i].enemyProjectileList[j].collision =
-							wallCollisionCheckFirstLevel(game.levelOneEnemyList[i].enemyProjectileList[j], 20)
-					}
-				}
-			}
+// leftThroughDoor returns the open door the player has driven out of the room through, if any.
+func (game *Game) leftThroughDoor() (roomDoor, bool) {
+	box := hitboxBounds(game.playerSprite)
+	doors := game.openDoors()
+	for i := 0; i < len(doors); i++ {
+		if (doors[i].side == "north" && box.Min.Y <= 0) || (doors[i].side == "south" && box.Max.Y >= ScreenHeight) ||
+			(doors[i].side == "west" && box.Min.X <= 0) || (doors[i].side == "east" && box.Max.X >= ScreenWidth) {
+			return doors[i], true
 		}
 	}
+	return roomDoor{}, false
+}
 
-	//player collides with enemy check
-	if len(game.levelOneEnemyList) > 0 {
-		for i := 0; i < len(game.levelOneEnemyList); i++ {
-			if game.levelOneEnemyList[i].collision == false {
-				enemyWidth, _ := game.levelOneEnemyList[i].leftPict.Size()
-				playerWidth, _ := game.playerSprite.upPict.Size()
-				death := playerCollisionWithEnemy(game.levelOneEnemyList[i], game.playerSprite, enemyWidth, playerWidth)
-				if death == 1 {
-					g.enemyAndPlayerCollisionAudioPlayer.Rewind()
-					g.enemyAndPlayerCollisionAudioPlayer.Play()
-					game.playerSprite.xLoc, game.playerSprite.yLoc = 190, ScreenHeight*0.72
-					game.deathCounter += death
-				}
-			}
-		}
+func (game *Game) setActiveRoom(index int) {
+	game.levelOneIsActive = index == 0
+	game.levelTwoIsActive = index == 1
+	game.levelThreeIsActive = index == 2
+	game.generatedRoomIsActive = index == generatedRoomIndex
+}
+
+// enterRoom moves the player through a door into the room it leads to. The player comes in just
+// inside the opposite wall, and that entrance is sealed.
+func (game *Game) enterRoom(door roomDoor) {
+	boundaryWidth := 25
+	box := hitboxBounds(game.playerSprite)
+	if door.side == "north" {
+		game.playerSprite.yLoc += ScreenHeight - boundaryWidth - 2 - box.Max.Y
+	} else if door.side == "south" {
+		game.playerSprite.yLoc += boundaryWidth + 2 - box.Min.Y
+	} else if door.side == "west" {
+		game.playerSprite.xLoc += ScreenWidth - boundaryWidth - 2 - box.Max.X
+	} else {
+		game.playerSprite.xLoc += boundaryWidth + 2 - box.Min.X
 	}
+	game.entrance = roomDoor{side: oppositeSide(door.side), from: door.from, to: door.to, leadsTo: game.currentLevel()}
+	if door.leadsTo == generatedRoomIndex {
+		game.buildGeneratedRoom()
+	}
+	game.setActiveRoom(door.leadsTo)
+	game.levelReached = door.leadsTo + 1
+	if door.leadsTo == generatedRoomIndex {
+		game.levelReached = generatedRoomIndex + game.generatedRooms
+	}
+	game.startNextLevel()
+	game.saveCheckpoint()
+}
 
-	//enemy projectile collides with player check
-	if len(game.levelOneEnemyList) > 0 {
-		for i := 0; i < len(game.levelOneEnemyList); i++ {
-			if len(game.levelOneEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelOneEnemyList[i].enemyProjectileList); j++ {
-					if game.levelOneEnemyList[i].enemyProjectileList[j].collision == false {
-						death := 0
-						game.levelOneEnemyList[i].enemyProjectileList[j].collision, death =
-							projectileCollisionWithPlayer(game.playerSprite,
-								game.levelOneEnemyList[i].enemyProjectileList[j], 61, 20)
-						if death == 1 {
-							g.playerDeathAudioPlayer.Rewind()
-							g.playerDeathAudioPlayer.Play()
-							game.playerSprite.xLoc, game.playerSprite.yLoc = 190, ScreenHeight*0.72
-							game.deathCounter += death
-						}
+// difficulty is how hard the settings made the game. Lethal walls are the normal game, solid walls
+// make it easier.
+// wallsSolid reports whether the walls of the current room block movement rather than cost a life.
+// Solid walls picked on the title screen apply to every room, otherwise each room sets its own rule.
+func (game *Game) wallsSolid() bool {
+	return game.solidWalls == true || game.currentRoom().solidWalls == true
+}
 
-					}
-				}
-			}
-		}
+func (game *Game) difficulty() string {
+	if game.solidWalls == true {
+		return "easy"
 	}
+	return "normal"
+}
 
-	//player projectile collides with enemy check
-	if len(game.projectileList) > 0 && len(game.levelOneEnemyList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			for j := 0; j < len(game.levelOneEnemyList); j++ {
-				enemyWidth, _ := game.levelOneEnemyList[j].upPict.Size()
-				if game.levelOneEnemyList[j].collision == false && game.projectileList[i].collision == false {
-					additionalScore := 0
-					game.levelOneEnemyList[j].collision, game.projectileList[i].collision, game.levelOneEnemyList[j].health, additionalScore =
-						projectileCollisionWithEnemy(game.levelOneEnemyList[j], game.projectileList[i], enemyWidth, 20)
-					game.score += additionalScore
-				}
-			}
-		}
+// campaignCheckpoint is where a player's campaign can be continued from: the furthest room they
+// have reached, the door they came in by, and their lives and score as they entered it.
+type campaignCheckpoint struct {
+	level    int
+	lives    int
+	score    int
+	entrance roomDoor
+}
+
+// saveCheckpoint saves the room the player just entered as their checkpoint, unless they have
+// already reached a later room. Generated rooms can't be rebuilt later, so they are never saved.
+func (game *Game) saveCheckpoint() {
+	if game.currentLevel() == generatedRoomIndex {
+		return
 	}
+	myDatabase := OpenDataBase(dbPath)
+	create_tables(myDatabase)
+	checkpoint := campaignCheckpoint{level: game.currentLevel(), lives: game.lives(), score: game.score, entrance: game.entrance}
+	if err := game.saveCampaign(myDatabase, checkpoint); err != nil {
+		//the run carries on, but the player is told their progress wasn't saved
+		log.Println("failed to save the checkpoint: ", err)
+		game.toasts = append(game.toasts, "Checkpoint not saved")
+	}
+	myDatabase.Close()
 }
 
-func (game *Game) manageLevel2CollisionDetection() {
-	if game.collectedGold == false {
-		game.collectedGold = game.gotGold(game.playerSprite, game.coinSprite)
+// lookUpCheckpoint finds the saved checkpoint, if any, for the username being typed on the title
+// screen. The database is only read again when the name changes.
+func (game *Game) lookUpCheckpoint() {
+	typedName := ""
+	for i := 0; i < len(game.userNameList); i++ {
+		typedName += game.userNameList[i]
+	}
+	if typedName == game.checkpointName {
+		return
 	}
+	game.checkpointName = typedName
+	myDatabase := OpenDataBase(dbPath)
+	create_tables(myDatabase)
+	game.checkpoint, game.hasCheckpoint = loadCampaign(myDatabase, typedName)
+	myDatabase.Close()
+}
 
-	//player collision with wall check
-	if game.playerAndWallCollision == false {
-		game.playerAndWallCollision = wallCollisionCheckSecondLevel(game.playerSprite, 61)
-	} else {
-		game.playerSprite.xLoc, game.playerSprite.yLoc = 100, 100
-		game.playerAndWallCollision = false
-		g.playerDeathAudioPlayer.Rewind()
-		g.playerDeathAudioPlayer.Play()
-		game.deathCounter += 1
+// continueCampaign starts the game in the checkpoint's room, with the lives and score the player
+// had when they first got there.
+func (game *Game) continueCampaign() {
+	boundaryWidth := 25
+	game.continued = true
+	game.setActiveRoom(game.checkpoint.level)
+	game.levelReached = game.checkpoint.level + 1
+	game.score = game.checkpoint.score
+	game.deathCounter = startingLives - game.checkpoint.lives
+	if lifeRules.scoreEvery > 0 {
+		game.nextLifeScore = (game.score/lifeRules.scoreEvery + 1) * lifeRules.scoreEvery
+	}
+	game.entrance = game.checkpoint.entrance
+
+	//come in just inside the middle of the door the checkpoint was entered by
+	opening := game.entrance.opening()
+	box := hitboxAt(game.playerSprite, 0, 0)
+	game.playerSprite.xLoc = (opening.Min.X+opening.Max.X)/2 - (box.Min.X+box.Max.X)/2
+	game.playerSprite.yLoc = (opening.Min.Y+opening.Max.Y)/2 - (box.Min.Y+box.Max.Y)/2
+	if game.entrance.side == "north" {
+		game.playerSprite.yLoc = boundaryWidth + 2 - box.Min.Y
+	} else if game.entrance.side == "south" {
+		game.playerSprite.yLoc = ScreenHeight - boundaryWidth - 2 - box.Max.Y
+	} else if game.entrance.side == "west" {
+		game.playerSprite.xLoc = boundaryWidth + 2 - box.Min.X
+	} else if game.entrance.side == "east" {
+		game.playerSprite.xLoc = ScreenWidth - boundaryWidth - 2 - box.Max.X
 	}
+	game.startNextLevel()
+}
 
-	//enemy collision with wall check
-	if len(game.levelTwoEnemyList) > 0 {
-		for i := 0; i < len(game.levelTwoEnemyList); i++ {
-			if game.levelTwoEnemyList[i].collision == false {
-				if game.levelTwoEnemyList[i].direction == "left" {
-					spriteWidth, _ := game.levelTwoEnemyList[i].leftPict.Size()
-					game.levelTwoEnemyList[i].collision = wallCollisionCheckSecondLevel(game.levelTwoEnemyList[i], spriteWidth)
-					if game.levelTwoEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelTwoEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelTwoEnemyList[i].direction == "right" {
-					spriteWidth, _ := game.levelTwoEnemyList[i].rightPict.Size()
-					game.levelTwoEnemyList[i].collision = wallCollisionCheckSecondLevel(game.levelTwoEnemyList[i], spriteWidth)
-					if game.levelTwoEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelTwoEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelTwoEnemyList[i].direction == "up" {
-					spriteWidth, _ := game.levelTwoEnemyList[i].upPict.Size()
-					game.levelTwoEnemyList[i].collision = wallCollisionCheckSecondLevel(game.levelTwoEnemyList[i], spriteWidth)
-					if game.levelTwoEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelTwoEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelTwoEnemyList[i].direction == "down" {
-					spriteWidth, _ := game.levelTwoEnemyList[i].downPict.Size()
-					game.levelTwoEnemyList[i].collision = wallCollisionCheckSecondLevel(game.levelTwoEnemyList[i], spriteWidth)
-					if game.levelTwoEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelTwoEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				}
-			} else {
-				if game.levelTwoEnemyList[i].health == 2 && game.levelTwoEnemyList[i].collision == true {
-					game.score += 300
-					game.levelTwoEnemyList[i].health = 0
-				}
-				if game.levelTwoEnemyList[i].health == 1 && game.levelTwoEnemyList[i].collision == true {
-					game.score += 200
-					game.levelTwoEnemyList[i].health = 0
-				}
-				game.levelTwoEnemyList[i].dx = 0
-				game.levelTwoEnemyList[i].dy = 0
+const (
+	generatedRoomIndex = 3
+	mazeColumns        = 6
+	mazeRows           = 5
+	mazeCellWidth      = 125
+	mazeCellHeight     = 130
+	mazeWallThickness  = 20
+	safeEntryRadius    = 260 //no enemy starts this close to the middle of the entrance cell
+)
+
+// generatedRoom is a room built by generateRoom, along with where its enemies go.
+type generatedRoom struct {
+	layout     room
+	enemySpots []image.Point
+}
+
+func mazeCell(col int, row int) image.Rectangle {
+	boundaryWidth := 25
+	return image.Rect(boundaryWidth+col*mazeCellWidth, boundaryWidth+row*mazeCellHeight,
+		boundaryWidth+(col+1)*mazeCellWidth, boundaryWidth+(row+1)*mazeCellHeight)
+}
+
+func mazeCellCenter(cell image.Point) image.Point {
+	bounds := mazeCell(cell.X, cell.Y)
+	return image.Pt((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)
+}
+
+// mazeDoor returns a door in the outer wall that opens into the given edge cell of the maze grid.
+func mazeDoor(side string, cell image.Point, leadsTo int) roomDoor {
+	bounds := mazeCell(cell.X, cell.Y)
+	margin := 15
+	if side == "north" || side == "south" {
+		return roomDoor{side: side, from: bounds.Min.X + margin, to: bounds.Max.X - margin, leadsTo: leadsTo}
+	}
+	return roomDoor{side: side, from: bounds.Min.Y + margin, to: bounds.Max.Y - margin, leadsTo: leadsTo}
+}
+
+// doorCell returns the cell of the maze grid a door in the outer wall opens into.
+func doorCell(door roomDoor) image.Point {
+	boundaryWidth := 25
+	col := (door.from - boundaryWidth) / mazeCellWidth
+	row := (door.from - boundaryWidth) / mazeCellHeight
+	if door.side == "north" {
+		return image.Pt(int(math.Min(float64(col), mazeColumns-1)), 0)
+	} else if door.side == "south" {
+		return image.Pt(int(math.Min(float64(col), mazeColumns-1)), mazeRows-1)
+	} else if door.side == "west" {
+		return image.Pt(0, int(math.Min(float64(row), mazeRows-1)))
+	}
+	return image.Pt(mazeColumns-1, int(math.Min(float64(row), mazeRows-1)))
+}
+
+// generateRoom builds a maze room from a seed. The same seed and entrance always give the same room.
+// Walls sit on the edges of a grid of cells, carved as a spanning tree from the entrance cell so every
+// cell, and so every exit, can be reached from the entrance, with a few extra openings for loops.
+// Enemies go in cells outside safeEntryRadius of the entrance, preferring ones far round the maze.
+func generateRoom(seed int64, entrance roomDoor, enemyCount int) generatedRoom {
+	rng := rand.New(rand.NewSource(seed))
+	eastOpen := make([][]bool, mazeColumns)
+	southOpen := make([][]bool, mazeColumns)
+	visited := make([][]bool, mazeColumns)
+	for col := 0; col < mazeColumns; col++ {
+		eastOpen[col] = make([]bool, mazeRows)
+		southOpen[col] = make([]bool, mazeRows)
+		visited[col] = make([]bool, mazeRows)
+	}
+	inGrid := func(cell image.Point) bool {
+		return cell.X >= 0 && cell.Y >= 0 && cell.X < mazeColumns && cell.Y < mazeRows
+	}
+	//open reports or sets the passage between two neighbouring cells
+	passage := func(a image.Point, b image.Point) *bool {
+		if a.X > b.X || a.Y > b.Y {
+			a, b = b, a
+		}
+		if b.X > a.X {
+			return &eastOpen[a.X][a.Y]
+		}
+		return &southOpen[a.X][a.Y]
+	}
+	steps := []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+	start := doorCell(entrance)
+	visited[start.X][start.Y] = true
+	stack := []image.Point{start}
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		var next []image.Point
+		for _, step := range steps {
+			neighbour := cell.Add(step)
+			if inGrid(neighbour) && visited[neighbour.X][neighbour.Y] == false {
+				next = append(next, neighbour)
 			}
 		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		neighbour := next[rng.Intn(len(next))]
+		*passage(cell, neighbour) = true
+		visited[neighbour.X][neighbour.Y] = true
+		stack = append(stack, neighbour)
 	}
-
-	//player projectile collides with wall check
-	if len(game.projectileList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			if game.projectileList[i].collision == false {
-				game.projectileList[i].xLoc += game.projectileList[i].dx
-				game.projectileList[i].yLoc += game.projectileList[i].dy
-				game.projectileList[i].collision = wallCollisionCheckSecondLevel(game.projectileList[i], 20)
+	for col := 0; col < mazeColumns; col++ {
+		for row := 0; row < mazeRows; row++ {
+			if rng.Intn(100) < 15 {
+				eastOpen[col][row] = true
+			}
+			if rng.Intn(100) < 15 {
+				southOpen[col][row] = true
 			}
 		}
 	}
 
-	//enemy projectile collides with wall check
-	if len(game.levelTwoEnemyList) > 0 {
-		for i := 0; i < len(game.levelTwoEnemyList); i++ {
-			if len(game.levelTwoEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelTwoEnemyList[i].enemyProjectileList); j++ {
-					if game.levelTwoEnemyList[i].enemyProjectileList[j].collision == false {
-						game.levelTwoEnemyList[i].enemyProjectileList[j].xLoc += game.levelTwoEnemyList[i].enemyProjectileList[j].dx
-						game.levelTwoEnemyList[i].enemyProjectileList[j].yLoc += game.levelTwoEnemyList[i].enemyProjectileList[j].dy
-						game.levelTwoEnemyList[i].enemyProjectileList[j].collision =
-							wallCollisionCheckSecondLevel(game.levelTwoEnemyList[i].enemyProjectileList[j], 20)
-					}
-				}
+	var generated generatedRoom
+	arena := image.Rect(25, 25, ScreenWidth-25, ScreenHeight-25)
+	halfWall := mazeWallThickness / 2
+	for col := 0; col < mazeColumns; col++ {
+		for row := 0; row < mazeRows; row++ {
+			bounds := mazeCell(col, row)
+			if col < mazeColumns-1 && eastOpen[col][row] == false {
+				wall := image.Rect(bounds.Max.X-halfWall, bounds.Min.Y-halfWall, bounds.Max.X+halfWall, bounds.Max.Y+halfWall)
+				generated.layout.walls = append(generated.layout.walls, wall.Intersect(arena))
+			}
+			if row < mazeRows-1 && southOpen[col][row] == false {
+				wall := image.Rect(bounds.Min.X-halfWall, bounds.Max.Y-halfWall, bounds.Max.X+halfWall, bounds.Max.Y+halfWall)
+				generated.layout.walls = append(generated.layout.walls, wall.Intersect(arena))
 			}
 		}
 	}
 
-	//player collides with enemy check
-	if len(game.levelTwoEnemyList) > 0 {
-		for i := 0; i < len(game.levelTwoEnemyList); i++ {
-			if game.levelTwoEnemyList[i].collision == false {
-				enemyWidth, _ := game.levelTwoEnemyList[i].leftPict.Size()
-				playerWidth, _ := game.playerSprite.upPict.Size()
-				death := playerCollisionWithEnemy(game.levelTwoEnemyList[i], game.playerSprite, enemyWidth, playerWidth)
-				if death == 1 {
-					g.enemyAndPlayerCollisionAudioPlayer.Rewind()
-					g.enemyAndPlayerCollisionAudioPlayer.Play()
-					game.playerSprite.xLoc, game.playerSprite.yLoc = 100, 100
-					game.deathCounter += death
-				}
+	//how many cells away from the entrance each cell is
+	distance := map[image.Point]int{start: 0}
+	queue := []image.Point{start}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, step := range steps {
+			neighbour := cell.Add(step)
+			if _, seen := distance[neighbour]; inGrid(neighbour) && seen == false && *passage(cell, neighbour) == true {
+				distance[neighbour] = distance[cell] + 1
+				queue = append(queue, neighbour)
 			}
 		}
 	}
 
-	//enemy projectile collides with player check
-	if len(game.levelTwoEnemyList) > 0 {
-		for i := 0; i < len(game.levelTwoEnemyList); i++ {
-			if len(game.levelTwoEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelTwoEnemyList[i].enemyProjectileList); j++ {
-					if game.levelTwoEnemyList[i].enemyProjectileList[j].collision == false {
-						death := 0
-						game.levelTwoEnemyList[i].enemyProjectileList[j].collision, death =
-							projectileCollisionWithPlayer(game.playerSprite,
-								game.levelTwoEnemyList[i].enemyProjectileList[j], 61, 20)
-						if death == 1 {
-							g.playerDeathAudioPlayer.Rewind()
-							g.playerDeathAudioPlayer.Play()
-							game.playerSprite.xLoc, game.playerSprite.yLoc = 100, 100
-							game.deathCounter += death
-						}
-
-					}
-				}
-			}
+	sides := []string{"north", "south", "east", "west"}
+	exitCount := 1 + rng.Intn(2)
+	for _, pick := range rng.Perm(len(sides)) {
+		side := sides[pick]
+		if side == entrance.side || len(generated.layout.doors) == exitCount {
+			continue
+		}
+		cell := image.Pt(rng.Intn(mazeColumns), rng.Intn(mazeRows))
+		if side == "north" {
+			cell.Y = 0
+		} else if side == "south" {
+			cell.Y = mazeRows - 1
+		} else if side == "west" {
+			cell.X = 0
+		} else {
+			cell.X = mazeColumns - 1
 		}
+		generated.layout.doors = append(generated.layout.doors, mazeDoor(side, cell, generatedRoomIndex))
 	}
 
-	//player projectile collides with enemy check
-	if len(game.projectileList) > 0 && len(game.levelTwoEnemyList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			for j := 0; j < len(game.levelTwoEnemyList); j++ {
-				enemyWidth, _ := game.levelTwoEnemyList[j].upPict.Size()
-				if game.levelTwoEnemyList[j].collision == false && game.projectileList[i].collision == false {
-					additionalScore := 0
-					game.levelTwoEnemyList[j].collision, game.projectileList[i].collision, game.levelTwoEnemyList[j].health, additionalScore =
-						projectileCollisionWithEnemy(game.levelTwoEnemyList[j], game.projectileList[i], enemyWidth, 20)
-					game.score += additionalScore
-				}
+	//cells a long way round the maze are filled first, and none within the safe radius are used
+	var far, near []image.Point
+	entry := mazeCellCenter(start)
+	for col := 0; col < mazeColumns; col++ {
+		for row := 0; row < mazeRows; row++ {
+			cell := image.Pt(col, row)
+			center := mazeCellCenter(cell)
+			if math.Hypot(float64(center.X-entry.X), float64(center.Y-entry.Y)) <= safeEntryRadius {
+				continue
+			}
+			if distance[cell] >= 3 {
+				far = append(far, cell)
+			} else {
+				near = append(near, cell)
 			}
 		}
 	}
+	rng.Shuffle(len(far), func(i, j int) { far[i], far[j] = far[j], far[i] })
+	rng.Shuffle(len(near), func(i, j int) { near[i], near[j] = near[j], near[i] })
+	free := append(far, near...)
+	for i := 0; i < enemyCount && i < len(free); i++ {
+		generated.enemySpots = append(generated.enemySpots, mazeCellCenter(free[i]))
+	}
+	generated.layout.objectives = []levelObjective{{kind: "clear"}}
+	//corridors of the generated maze are too tight for lethal walls
+	generated.layout.solidWalls = true
+	return generated
 }
 
-func (game *Game) manageLevel3CollisionDetection() {
-	if game.collectedGold == false {
-		game.collectedGold = game.gotGold(game.playerSprite, game.coinSprite)
+// newRoomPict draws the background of a generated room, since there is no map picture for it.
+func newRoomPict(walls []image.Rectangle) *ebiten.Image {
+	wallColor := color.RGBA{0x3b, 0x8e, 0xa8, 0xff}
+	wallEdgeColor := color.RGBA{0x1f, 0x55, 0x66, 0xff}
+	floorColor := color.RGBA{0x26, 0x2a, 0x33, 0xff}
+	boundaryWidth := 25.0
+
+	pict := ebiten.NewImage(ScreenWidth, ScreenHeight)
+	pict.Fill(wallColor)
+	ebitenutil.DrawRect(pict, boundaryWidth, boundaryWidth, ScreenWidth-boundaryWidth*2, ScreenHeight-boundaryWidth*2, floorColor)
+	for i := 0; i < len(walls); i++ {
+		x, y := float64(walls[i].Min.X), float64(walls[i].Min.Y)
+		w, h := float64(walls[i].Dx()), float64(walls[i].Dy())
+		ebitenutil.DrawRect(pict, x, y, w, h, wallEdgeColor)
+		ebitenutil.DrawRect(pict, x+2, y+2, w-4, h-4, wallColor)
 	}
+	return pict
+}
 
-	//player collision with wall check
-	if game.playerAndWallCollision == false {
-		game.playerAndWallCollision = wallCollisionCheckThirdLevel(game.playerSprite, 61)
-	} else {
-		game.playerSprite.xLoc, game.playerSprite.yLoc = 600, 100
-		game.playerAndWallCollision = false
-		g.playerDeathAudioPlayer.Rewind()
-		g.playerDeathAudioPlayer.Play()
-		game.deathCounter += 1
+// buildGeneratedRoom makes the next room of the endless maze and keeps it as the generated room being
+// played. Each room's seed follows on from the run's seed, so a run can be replayed from its seed.
+func (game *Game) buildGeneratedRoom() {
+	enemyCount := int(math.Min(float64(2+game.generatedRooms/2), 6))
+	game.generatedLayout = generateRoom(game.endlessSeed+int64(game.generatedRooms), game.entrance, enemyCount)
+	game.generatedRooms += 1
+	if game.generatedMap.upPict != nil {
+		game.generatedMap.upPict.Dispose()
 	}
+	game.generatedMap.upPict = newRoomPict(game.generatedLayout.layout.walls)
+	game.spawnedGeneratedRoom = false
+}
 
-	//enemy collision with wall check
-	if len(game.levelThreeEnemyList) > 0 {
-		for i := 0; i < len(game.levelThreeEnemyList); i++ {
-			if game.levelThreeEnemyList[i].collision == false {
-				if game.levelThreeEnemyList[i].direction == "left" {
-					spriteWidth, _ := game.levelThreeEnemyList[i].leftPict.Size()
-					game.levelThreeEnemyList[i].collision = wallCollisionCheckThirdLevel(game.levelThreeEnemyList[i], spriteWidth)
-					if game.levelThreeEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelThreeEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelThreeEnemyList[i].direction == "right" {
-					spriteWidth, _ := game.levelThreeEnemyList[i].rightPict.Size()
-					game.levelThreeEnemyList[i].collision = wallCollisionCheckThirdLevel(game.levelThreeEnemyList[i], spriteWidth)
-					if game.levelThreeEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelThreeEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelThreeEnemyList[i].direction == "up" {
-					spriteWidth, _ := game.levelThreeEnemyList[i].upPict.Size()
-					game.levelThreeEnemyList[i].collision = wallCollisionCheckThirdLevel(game.levelThreeEnemyList[i], spriteWidth)
-					if game.levelThreeEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelThreeEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				} else if game.levelThreeEnemyList[i].direction == "down" {
-					spriteWidth, _ := game.levelThreeEnemyList[i].downPict.Size()
-					game.levelThreeEnemyList[i].collision = wallCollisionCheckThirdLevel(game.levelThreeEnemyList[i], spriteWidth)
-					if game.levelThreeEnemyList[i].collision == true && spriteWidth == 50 {
-						g.monsterEnemyDeathAudioPlayer.Rewind()
-						g.monsterEnemyDeathAudioPlayer.Play()
-					} else if game.levelThreeEnemyList[i].collision == true && spriteWidth != 50 {
-						g.humanEnemyDeathAudioPlayer.Rewind()
-						g.humanEnemyDeathAudioPlayer.Play()
-					}
-				}
-			} else {
-				if game.levelThreeEnemyList[i].health == 2 && game.levelThreeEnemyList[i].collision == true {
-					game.score += 300
-					game.levelThreeEnemyList[i].health = 0
-				}
-				if game.levelThreeEnemyList[i].health == 1 && game.levelThreeEnemyList[i].collision == true {
-					game.score += 200
-					game.levelThreeEnemyList[i].health = 0
-				}
-				game.levelThreeEnemyList[i].dx = 0
-				game.levelThreeEnemyList[i].dy = 0
-			}
+func (game *Game) roomSpawned() bool {
+	if game.levelTwoIsActive {
+		return game.spawnedLevel2Enemies
+	} else if game.levelThreeIsActive {
+		return game.spawnedLevel3Enemies
+	} else if game.generatedRoomIsActive {
+		return game.spawnedGeneratedRoom
+	}
+	return game.spawnedLevel1Enemies
+}
+
+// currentRoom returns the layout of the room the player is in. Generated rooms aren't part of rooms,
+// the one being played is kept on the game instead.
+func (game *Game) currentRoom() room {
+	if game.generatedRoomIsActive {
+		return game.generatedLayout.layout
+	}
+	return rooms[game.currentLevel()]
+}
+
+func (game *Game) currentLevel() int {
+	if game.levelTwoIsActive {
+		return 1
+	} else if game.levelThreeIsActive {
+		return 2
+	} else if game.generatedRoomIsActive {
+		return generatedRoomIndex
+	}
+	return 0
+}
+
+func (game *Game) enemiesLeft() int {
+	enemyList := game.activeEnemyList()
+	left := 0
+	for i := 0; i < len(enemyList); i++ {
+		if enemyList[i].collision == false {
+			left += 1
 		}
 	}
+	return left
+}
 
-	//player projectile collides with wall check
-	if len(game.projectileList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			if game.projectileList[i].collision == false {
-				game.projectileList[i].xLoc += game.projectileList[i].dx
-				game.projectileList[i].yLoc += game.projectileList[i].dy
-				game.projectileList[i].collision = wallCollisionCheckThirdLevel(game.projectileList[i], 20)
-			}
+func (game *Game) objectiveMet(objective levelObjective) bool {
+	if objective.kind == "clear" {
+		return game.enemiesLeft() == 0
+	} else if objective.kind == "exit" {
+		return hitboxBounds(game.playerSprite).Overlaps(objective.exit)
+	} else if objective.kind == "survive" {
+		return game.levelTicks >= objective.surviveTicks
+	} else if objective.kind == "collect" {
+		return game.itemsCollected >= objective.itemCount
+	}
+	return false
+}
+
+func (game *Game) levelComplete() bool {
+	if game.roomSpawned() == false {
+		return false
+	}
+	objectives := game.currentRoom().objectives
+	for i := 0; i < len(objectives); i++ {
+		if game.objectiveMet(objectives[i]) == false {
+			return false
 		}
 	}
+	return true
+}
 
-	//enemy projectile collides with wall check
-	if len(game.levelThreeEnemyList) > 0 {
-		for i := 0; i < len(game.levelThreeEnemyList); i++ {
-			if len(game.levelThreeEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelThreeEnemyList[i].enemyProjectileList); j++ {
-					if game.levelThreeEnemyList[i].enemyProjectileList[j].collision == false {
-						game.levelThreeEnemyList[i].enemyProjectileList[j].xLoc += game.levelThreeEnemyList[i].enemyProjectileList[j].dx
-						game.levelThreeEnemyList[i].enemyProjectileList[j].yLoc += game.levelThreeEnemyList[i].enemyProjectileList[j].dy
-						game.levelThreeEnemyList[i].enemyProjectileList[j].collision =
-							wallCollisionCheckThirdLevel(game.levelThreeEnemyList[i].enemyProjectileList[j], 20)
-					}
-				}
-			}
+// objectiveText describes the first objective of the current level that still needs to be met.
+func (game *Game) objectiveText() string {
+	objectives := game.currentRoom().objectives
+	for i := 0; i < len(objectives); i++ {
+		if game.objectiveMet(objectives[i]) == true {
+			continue
 		}
+		if objectives[i].kind == "clear" {
+			return "Destroy all enemies (" + strconv.Itoa(game.enemiesLeft()) + " left)"
+		} else if objectives[i].kind == "exit" {
+			return "Reach the EXIT"
+		} else if objectives[i].kind == "survive" {
+			secondsLeft := (objectives[i].surviveTicks - game.levelTicks + ebiten.MaxTPS() - 1) / ebiten.MaxTPS()
+			return "Survive " + strconv.Itoa(secondsLeft) + "s"
+		} else if objectives[i].kind == "collect" {
+			return "Collect items (" + strconv.Itoa(game.itemsCollected) + "/" + strconv.Itoa(objectives[i].itemCount) + ")"
+		}
+	}
+	if len(game.openDoors()) > 0 {
+		return "Leave through an open door"
 	}
+	return ""
+}
 
-	//player collides with enemy check
-	if len(game.levelThreeEnemyList) > 0 {
-		for i := 0; i < len(game.levelThreeEnemyList); i++ {
-			if game.levelThreeEnemyList[i].collision == false {
-				enemyWidth, _ := game.levelThreeEnemyList[i].leftPict.Size()
-				playerWidth, _ := game.playerSprite.upPict.Size()
-				death := playerCollisionWithEnemy(game.levelThreeEnemyList[i], game.playerSprite, enemyWidth, playerWidth)
-				if death == 1 {
-					g.enemyAndPlayerCollisionAudioPlayer.Rewind()
-					g.enemyAndPlayerCollisionAudioPlayer.Play()
-					game.playerSprite.xLoc, game.playerSprite.yLoc = 600, 100
-					game.deathCounter += death
-				}
-			}
+func (game *Game) startNextLevel() {
+	game.levelTicks = 0
+	game.itemsCollected = 0
+	game.levelShotsFired = 0
+	game.levelShotsHit = 0
+	game.levelDamage = 0
+}
+
+// bonusRules sets the end of room bonuses. The accuracy bonus is scaled by the share of shots that
+// hit an enemy, and the time bonus runs down to nothing over parSeconds.
+type bonusRules struct {
+	clear      int
+	flawless   int
+	accuracy   int
+	time       int
+	bestSplit  int
+	parSeconds int
+	tallyTicks int //how long each line of the results screen takes to count up
+}
+
+var levelBonusRules = bonusRules{clear: 1000, flawless: 1500, accuracy: 1000, time: 2000, bestSplit: 1000, parSeconds: 90, tallyTicks: 45}
+
+type bonusTally struct {
+	name   string
+	points int
+}
+
+// tallyBonuses works out the bonuses earned in the room the player is leaving.
+func (game *Game) tallyBonuses() []bonusTally {
+	rules := levelBonusRules
+	clearBonus, flawlessBonus, accuracyBonus, accuracy := 0, 0, 0, 0
+	if game.enemiesLeft() == 0 {
+		clearBonus = rules.clear
+	}
+	if game.levelDamage == 0 {
+		flawlessBonus = rules.flawless
+	}
+	if game.levelShotsFired > 0 {
+		accuracy = 100 * game.levelShotsHit / game.levelShotsFired
+		accuracyBonus = rules.accuracy * game.levelShotsHit / game.levelShotsFired
+	}
+	seconds := game.levelTicks / ebiten.MaxTPS()
+	timeBonus := 0
+	if seconds < rules.parSeconds {
+		timeBonus = rules.time * (rules.parSeconds - seconds) / rules.parSeconds
+	}
+	//beating your own best time for the room is worth a bonus of its own
+	bestSplitName, bestSplitBonus := "No best time yet", 0
+	if best, found := game.bestSplits[game.currentLevel()]; found == true {
+		bestSplitName = "Best time " + clockText(best)
+		if game.levelTicks < best {
+			bestSplitName = "New best, was " + clockText(best)
+			bestSplitBonus = rules.bestSplit
 		}
 	}
+	return []bonusTally{
+		{"Room clear", clearBonus},
+		{"Flawless", flawlessBonus},
+		{"Accuracy " + strconv.Itoa(accuracy) + "%", accuracyBonus},
+		{"Time " + clockText(game.levelTicks), timeBonus},
+		{bestSplitName, bestSplitBonus},
+	}
+}
 
-	//enemy projectile collides with player check
-	if len(game.levelThreeEnemyList) > 0 {
-		for i := 0; i < len(game.levelThreeEnemyList); i++ {
-			if len(game.levelThreeEnemyList[i].enemyProjectileList) > 0 {
-				for j := 0; j < len(game.levelThreeEnemyList[i].enemyProjectileList); j++ {
-					if game.levelThreeEnemyList[i].enemyProjectileList[j].collision == false {
-						death := 0
-						game.levelThreeEnemyList[i].enemyProjectileList[j].collision, death =
-							projectileCollisionWithPlayer(game.playerSprite,
-								game.levelThreeEnemyList[i].enemyProjectileList[j], 61, 20)
-						if death == 1 {
-							game.playerSprite.xLoc, game.playerSprite.yLoc = 600, 100
-							g.playerDeathAudioPlayer.Rewind()
-							g.playerDeathAudioPlayer.Play()
-							game.deathCounter += death
-						}
+// recordSplit keeps the time the room just finished took, and saves it as the player's best time for
+// the room if it beats the old one. Generated rooms are different every time, so they have no best.
+func (game *Game) recordSplit() {
+	game.splits = append(game.splits, game.levelTicks)
+	game.splitLevels = append(game.splitLevels, game.levelReached)
+	if game.currentLevel() == generatedRoomIndex {
+		return
+	}
+	if best, found := game.bestSplits[game.currentLevel()]; found == true && best <= game.levelTicks {
+		return
+	}
+	game.bestSplits[game.currentLevel()] = game.levelTicks
+	myDatabase := OpenDataBase(dbPath)
+	create_tables(myDatabase)
+	game.saveBestSplit(myDatabase, game.currentLevel(), game.levelTicks)
+	myDatabase.Close()
+}
 
-					}
-				}
-			}
+// clockText formats a number of ticks as minutes and seconds, like 1:05.
+func clockText(ticks int) string {
+	seconds := ticks / ebiten.MaxTPS()
+	if seconds%60 < 10 {
+		return strconv.Itoa(seconds/60) + ":0" + strconv.Itoa(seconds%60)
+	}
+	return strconv.Itoa(seconds/60) + ":" + strconv.Itoa(seconds%60)
+}
+
+// showResults puts up the results screen for the room just finished. Once it is closed the player
+// goes through door, or wins the game if door leads nowhere.
+func (game *Game) showResults(door roomDoor) {
+	game.results = game.tallyBonuses()
+	game.achievementEvent("roomComplete")
+	game.recordSplit()
+	game.resultsTicks = 0
+	game.resultsDoor = door
+	game.showingResults = true
+}
+
+func (game *Game) resultsFinished() bool {
+	return game.resultsTicks >= len(game.results)*levelBonusRules.tallyTicks
+}
+
+// shownBonus is how far the results screen has counted up bonus i.
+func (game *Game) shownBonus(i int) int {
+	progress := float64(game.resultsTicks-i*levelBonusRules.tallyTicks) / float64(levelBonusRules.tallyTicks)
+	progress = math.Max(0, math.Min(progress, 1))
+	return int(float64(game.results[i].points) * progress)
+}
+
+// manageResults counts up the bonuses. ENTER skips to the end of the count, and once it is done
+// banks the bonuses and moves on.
+func (game *Game) manageResults() {
+	game.resultsTicks += 1
+	if inpututil.IsKeyJustReleased(ebiten.KeyEnter) == false {
+		return
+	}
+	if game.resultsFinished() == false {
+		game.resultsTicks = len(game.results) * levelBonusRules.tallyTicks
+		return
+	}
+	for i := 0; i < len(game.results); i++ {
+		game.score += game.results[i].points
+	}
+	game.showingResults = false
+	if game.resultsDoor.leadsTo < 0 {
+		game.setActiveRoom(-1)
+		game.gameWon = true
+		game.achievementEvent("won")
+	} else {
+		game.enterRoom(game.resultsDoor)
+	}
+}
+
+type achievement struct {
+	id          string
+	name        string
+	description string
+}
+
+var achievements = []achievement{
+	{"hold_fire", "Hold Your Fire", "Clear room 1 without firing"},
+	{"triple_kill", "Triple Threat", "Kill 3 enemies within 2 seconds"},
+	{"untouchable", "Untouchable", "Win with all hearts"},
+	{"crossfire", "Caught in the Middle", "Get an enemy shot by another"},
+	{"marksman", "Marksman", "Finish a room with every shot on target"},
+	{"on_a_roll", "On a Roll", "Reach a combo of 5"},
+}
+
+const toastTicks = 180
+
+// achievementEvent checks the achievements that can be earned by an event: "kill" for a kill the
+// player made, "crossfire" for an enemy killed by a shot the player baited, "roomComplete" or "won".
+func (game *Game) achievementEvent(event string) {
+	if event == "kill" {
+		//keep the times of the last 3 kills the player caused
+		game.killTicks = append(game.killTicks, game.runTicks)
+		if len(game.killTicks) > 3 {
+			game.killTicks = game.killTicks[len(game.killTicks)-3:]
+		}
+		if len(game.killTicks) == 3 && game.killTicks[2]-game.killTicks[0] <= 2*ebiten.MaxTPS() {
+			game.unlock("triple_kill")
+		}
+		if game.combo >= 5 {
+			game.unlock("on_a_roll")
+		}
+	} else if event == "crossfire" {
+		game.unlock("crossfire")
+	} else if event == "roomComplete" {
+		if game.currentLevel() == 0 && game.levelShotsFired == 0 {
+			game.unlock("hold_fire")
+		}
+		if game.levelShotsFired > 0 && game.levelShotsHit == game.levelShotsFired {
+			game.unlock("marksman")
+		}
+	} else if event == "won" {
+		if game.lives() >= startingLives {
+			game.unlock("untouchable")
 		}
 	}
+}
 
-	//player projectile collides with enemy check
-	if len(game.projectileList) > 0 && len(game.levelThreeEnemyList) > 0 {
-		for i := 0; i < len(game.projectileList); i++ {
-			for j := 0; j < len(game.levelThreeEnemyList); j++ {
-				enemyWidth, _ := game.levelThreeEnemyList[j].upPict.Size()
-				if game.levelThreeEnemyList[j].collision == false && game.projectileList[i].collision == false {
-					additionalScore := 0
-					game.levelThreeEnemyList[j].collision, game.projectileList[i].collision, game.levelThreeEnemyList[j].health, additionalScore =
-						projectileCollisionWithEnemy(game.levelThreeEnemyList[j], game.projectileList[i], enemyWidth, 20)
-					game.score += additionalScore
-				}
-			}
+// unlock gives the player an achievement they don't have yet, saves it and announces it.
+func (game *Game) unlock(id string) {
+	if game.unlocked == nil || game.unlocked[id] == true {
+		return
+	}
+	game.unlocked[id] = true
+	for i := 0; i < len(achievements); i++ {
+		if achievements[i].id == id {
+			game.toasts = append(game.toasts, "Achievement unlocked: "+achievements[i].name)
 		}
 	}
+	myDatabase := OpenDataBase(dbPath)
+	create_tables(myDatabase)
+	game.saveAchievement(myDatabase, id)
+	myDatabase.Close()
+}
+
+// manageToasts shows each queued toast for a while, one after another.
+func (game *Game) manageToasts() {
+	if len(game.toasts) == 0 {
+		return
+	}
+	game.toastTicks += 1
+	if game.toastTicks >= toastTicks {
+		game.toasts = game.toasts[1:]
+		game.toastTicks = 0
+	}
 }
 
+// checkLevel moves the player into the next room once they leave the current one through an open
+// door, and wins the game once the last room is complete.
 func (game *Game) checkLevel() {
 	if game.gameOver == false {
-		if game.score < 1000 {
+		if game.levelTwoIsActive == false && game.levelThreeIsActive == false && game.generatedRoomIsActive == false &&
+			game.gameWon == false {
 			game.levelOneIsActive = true
-			game.levelTwoIsActive = false
-			game.levelThreeIsActive = false
-		} else if game.score >= 1000 && game.score < 2000 && game.levelOneIsActive == true {
-			game.levelOneIsActive = false
-			game.levelTwoIsActive = true
-			game.levelThreeIsActive = false
-			game.playerSprite.xLoc, game.playerSprite.yLoc = 100, 100
-		} else if game.score >= 1000 && game.score < 2000 && game.levelOneIsActive == false {
-			game.levelOneIsActive = false
-			game.levelTwoIsActive = true
-			game.levelThreeIsActive = false
-		} else if game.score >= 2000 && game.levelTwoIsActive == true {
-			game.levelOneIsActive = false
-			game.levelTwoIsActive = false
-			game.levelThreeIsActive = true
-			game.playerSprite.xLoc, game.playerSprite.yLoc = 600, 100
-		} else if game.score >= 2000 && game.score < 3000 && game.levelThreeIsActive == true {
-			game.levelOneIsActive = false
-			game.levelTwoIsActive = false
-			game.levelThreeIsActive = true
-		} else if game.score >= 3000 && game.gameWon == false {
-			game.levelOneIsActive = false
-			game.levelTwoIsActive = false
-			game.levelThreeIsActive = false
-			game.gameWon = true
-		} else {
-			game.gameWon = true
+		}
+		if game.startGame == false || game.gameWon == true || game.showingResults == true {
+			return
+		}
+		game.levelTicks += 1
+		game.runTicks += 1
+		if len(game.roomDoors()) == 0 && game.levelComplete() {
+			game.showResults(roomDoor{leadsTo: -1})
+		} else if door, left := game.leftThroughDoor(); left == true {
+			game.showResults(door)
 		}
 	} else {
-		game.levelOneIsActive = true
-		game.levelTwoIsActive = false
-		game.levelThreeIsActive = false
+		game.setActiveRoom(0)
 	}
 }
 
@@ -2067,7 +3551,7 @@ func (game *Game) Update() error {
 	} else {
 		game.gameOver = false
 	}
-	if game.score >= 3000 {
+	if game.gameWon == true {
 		if game.playedWinSound == false {
 			game.playedWinSound = true
 			g.winAudioPlayer.Rewind()
@@ -2078,10 +3562,16 @@ func (game *Game) Update() error {
 	}
 
 	if game.startGame == false {
+		game.lookUpCheckpoint()
 		game.getUserName()
+		game.getSettings()
+	} else if game.showingResults == true {
+		game.manageResults()
 	} else if game.startGame == true && game.levelOneIsActive == true && game.gameOver == false {
 		game.spawnLevel1Enemies()
+		previousEnemyLocations := game.enemyLocations()
 		game.movementLevel1Enemies()
+		game.resolveEnemyWallMovement(previousEnemyLocations)
 		game.changeTankDirection()
 		game.changeTankTopperDirection()
 		game.playerShootFireball()
@@ -2089,7 +3579,9 @@ func (game *Game) Update() error {
 		game.manageLevel1CollisionDetection()
 	} else if game.startGame == true && game.levelTwoIsActive == true && game.gameOver == false {
 		game.spawnLevel2Enemies()
+		previousEnemyLocations := game.enemyLocations()
 		game.movementLevel2Enemies()
+		game.resolveEnemyWallMovement(previousEnemyLocations)
 		game.changeTankDirection()
 		game.changeTankTopperDirection()
 		game.playerShootFireball()
@@ -2097,25 +3589,37 @@ func (game *Game) Update() error {
 		game.manageLevel2CollisionDetection()
 	} else if game.startGame == true && game.levelThreeIsActive == true && game.gameOver == false && game.gameWon == false {
 		game.spawnLevel3Enemies()
+		previousEnemyLocations := game.enemyLocations()
 		game.movementLevel3Enemies()
+		game.resolveEnemyWallMovement(previousEnemyLocations)
 		game.changeTankDirection()
 		game.changeTankTopperDirection()
 		game.playerShootFireball()
 		game.manageTankTopperOffset()
 		game.manageLevel3CollisionDetection()
+	} else if game.startGame == true && game.generatedRoomIsActive == true && game.gameOver == false && game.gameWon == false {
+		game.spawnGeneratedRoomEnemies()
+		previousEnemyLocations := game.enemyLocations()
+		game.movementGeneratedRoomEnemies()
+		game.resolveEnemyWallMovement(previousEnemyLocations)
+		game.changeTankDirection()
+		game.changeTankTopperDirection()
+		game.playerShootFireball()
+		game.manageTankTopperOffset()
+		game.manageGeneratedRoomCollisionDetection()
 	} else if game.startGame == true && game.gameOver == true && game.dbEntryComplete == false {
-		myDatabase := OpenDataBase("./LeaderBoard.db")
+		myDatabase := OpenDataBase(dbPath)
 		create_tables(myDatabase)
 		game.addGameEntry(myDatabase)
 		game.dbEntryComplete = true
-		game.allScores = true
+		game.leaderboardTab = 2
 		myDatabase.Close()
 	} else if game.startGame == true && game.gameWon == true && game.dbEntryComplete == false {
-		myDatabase := OpenDataBase("./LeaderBoard.db")
+		myDatabase := OpenDataBase(dbPath)
 		create_tables(myDatabase)
 		game.addGameEntry(myDatabase)
 		game.dbEntryComplete = true
-		game.allScores = true
+		game.leaderboardTab = 2
 		myDatabase.Close()
 	} else if game.startGame == true && game.gameWon == true && game.dbEntryComplete == true && game.processedDB == false {
 		game.processDBtoMaps()
@@ -2135,6 +3639,19 @@ func (game *Game) Update() error {
 		game.manageTankTopperOffset()
 		game.manageLevel1CollisionDetection()
 	}
+	game.settleWallContacts(&game.playerProjectiles)
+	game.settleWallContacts(&game.enemyProjectiles)
+	game.playerProjectiles.prune()
+	game.enemyProjectiles.prune()
+	game.manageImpacts()
+	game.manageToasts()
+	if game.startGame == true && game.showingResults == false && game.gameOver == false && game.gameWon == false {
+		game.manageCombo()
+	}
+	if game.startGame == true && game.gameOver == false && game.gameWon == false {
+		game.manageExtraLives()
+		game.manageCollectibles()
+	}
 	return nil
 }
 
@@ -2154,6 +3671,46 @@ func (game Game) Draw(screen *ebiten.Image) {
 			game.drawOps.GeoM.Reset()
 			text.Draw(screen, "Press ENTER to start Berserk/Tank game.", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.45, color.Black)
 		}
+
+		game.drawOps.GeoM.Reset()
+		if game.mouseAim == true {
+			text.Draw(screen, "F1 - Aim: Mouse", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.52, colornames.White)
+		} else {
+			text.Draw(screen, "F1 - Aim: Keyboard (W/A/S/D)", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.52, colornames.White)
+		}
+		if game.tankControls == true {
+			text.Draw(screen, "F2 - Controls: Tank", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.57, colornames.White)
+		} else {
+			text.Draw(screen, "F2 - Controls: Classic", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.57, colornames.White)
+		}
+		if game.solidWalls == true {
+			text.Draw(screen, "F3 - Walls: Solid everywhere", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.62, colornames.White)
+		} else {
+			text.Draw(screen, "F3 - Walls: Set by each room", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.62, colornames.White)
+		}
+		if game.ricochetShells == true {
+			text.Draw(screen, "F4 - Shells: Ricochet once", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.67, colornames.White)
+		} else {
+			text.Draw(screen, "F4 - Shells: Stop at walls", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.67, colornames.White)
+		}
+		text.Draw(screen, "F5 - Tank hits: "+collisionModeName(game.playerSprite.pixelPerfect), mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.72, colornames.White)
+		text.Draw(screen, "F6 - Enemy hits: "+collisionModeName(game.personEnemy.pixelPerfect), mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.77, colornames.White)
+		text.Draw(screen, "F7 - Shell hits: "+collisionModeName(game.fireball.pixelPerfect), mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.82, colornames.White)
+		if game.friendlyFire == true {
+			text.Draw(screen, "F8 - Enemy friendly fire: On", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.87, colornames.White)
+		} else {
+			text.Draw(screen, "F8 - Enemy friendly fire: Off", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.87, colornames.White)
+		}
+		if game.endlessMode == true {
+			text.Draw(screen, "F9 - Endless maze: On", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.92, colornames.White)
+		} else {
+			text.Draw(screen, "F9 - Endless maze: Off", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.92, colornames.White)
+		}
+		if game.hasCheckpoint == true {
+			continueText := "F10 - Continue from room " + strconv.Itoa(game.checkpoint.level+1) +
+				" (" + strconv.Itoa(game.checkpoint.score) + " pts)"
+			text.Draw(screen, continueText, mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.97, colornames.Gold)
+		}
 	}
 	if game.startGame == true && game.gameOver == false && game.gameWon == false {
 
@@ -2184,27 +3741,21 @@ func (game Game) Draw(screen *ebiten.Image) {
 				}
 			}
 
-			if len(game.levelOneEnemyList) > 0 {
-				for i := 0; i < len(game.levelOneEnemyList); i++ {
-					if len(game.levelOneEnemyList[i].enemyProjectileList) > 0 {
-						for j := 0; j < len(game.levelOneEnemyList[i].enemyProjectileList); j++ {
-							if game.levelOneEnemyList[i].enemyProjectileList[j].collision == false {
-								game.drawOps.GeoM.Reset()
-								game.drawOps.GeoM.Translate(float64(game.levelOneEnemyList[i].enemyProjectileList[j].xLoc),
-									float64(game.levelOneEnemyList[i].enemyProjectileList[j].yLoc))
-								screen.DrawImage(game.levelOneEnemyList[i].enemyProjectileList[j].upPict, &game.drawOps)
-							}
-						}
-					}
+			for i := 0; i < len(game.enemyProjectiles.live); i++ {
+				if game.enemyProjectiles.live[i].collision == false {
+					game.drawOps.GeoM.Reset()
+					game.drawOps.GeoM.Translate(float64(game.enemyProjectiles.live[i].xLoc),
+						float64(game.enemyProjectiles.live[i].yLoc))
+					screen.DrawImage(game.enemyProjectiles.live[i].upPict, &game.drawOps)
 				}
 			}
 
-			if len(game.projectileList) > 0 {
-				for i := 0; i < len(game.projectileList); i++ {
-					if game.projectileList[i].collision == false {
+			if len(game.playerProjectiles.live) > 0 {
+				for i := 0; i < len(game.playerProjectiles.live); i++ {
+					if game.playerProjectiles.live[i].collision == false {
 						game.drawOps.GeoM.Reset()
-						game.drawOps.GeoM.Translate(float64(game.projectileList[i].xLoc), float64(game.projectileList[i].yLoc))
-						screen.DrawImage(game.projectileList[i].upPict, &game.drawOps)
+						game.drawOps.GeoM.Translate(float64(game.playerProjectiles.live[i].xLoc), float64(game.playerProjectiles.live[i].yLoc))
+						screen.DrawImage(game.playerProjectiles.live[i].upPict, &game.drawOps)
 					}
 				}
 			}
@@ -2236,27 +3787,21 @@ func (game Game) Draw(screen *ebiten.Image) {
 				}
 			}
 
-			if len(game.levelTwoEnemyList) > 0 {
-				for i := 0; i < len(game.levelTwoEnemyList); i++ {
-					if len(game.levelTwoEnemyList[i].enemyProjectileList) > 0 {
-						for j := 0; j < len(game.levelTwoEnemyList[i].enemyProjectileList); j++ {
-							if game.levelTwoEnemyList[i].enemyProjectileList[j].collision == false {
-								game.drawOps.GeoM.Reset()
-								game.drawOps.GeoM.Translate(float64(game.levelTwoEnemyList[i].enemyProjectileList[j].xLoc),
-									float64(game.levelTwoEnemyList[i].enemyProjectileList[j].yLoc))
-								screen.DrawImage(game.levelTwoEnemyList[i].enemyProjectileList[j].upPict, &game.drawOps)
-							}
-						}
-					}
+			for i := 0; i < len(game.enemyProjectiles.live); i++ {
+				if game.enemyProjectiles.live[i].collision == false {
+					game.drawOps.GeoM.Reset()
+					game.drawOps.GeoM.Translate(float64(game.enemyProjectiles.live[i].xLoc),
+						float64(game.enemyProjectiles.live[i].yLoc))
+					screen.DrawImage(game.enemyProjectiles.live[i].upPict, &game.drawOps)
 				}
 			}
 
-			if len(game.projectileList) > 0 {
-				for i := 0; i < len(game.projectileList); i++ {
-					if game.projectileList[i].collision == false {
+			if len(game.playerProjectiles.live) > 0 {
+				for i := 0; i < len(game.playerProjectiles.live); i++ {
+					if game.playerProjectiles.live[i].collision == false {
 						game.drawOps.GeoM.Reset()
-						game.drawOps.GeoM.Translate(float64(game.projectileList[i].xLoc), float64(game.projectileList[i].yLoc))
-						screen.DrawImage(game.projectileList[i].upPict, &game.drawOps)
+						game.drawOps.GeoM.Translate(float64(game.playerProjectiles.live[i].xLoc), float64(game.playerProjectiles.live[i].yLoc))
+						screen.DrawImage(game.playerProjectiles.live[i].upPict, &game.drawOps)
 					}
 				}
 			}
@@ -2288,34 +3833,137 @@ func (game Game) Draw(screen *ebiten.Image) {
 				}
 			}
 
-			if len(game.levelThreeEnemyList) > 0 {
-				for i := 0; i < len(game.levelThreeEnemyList); i++ {
-					if len(game.levelThreeEnemyList[i].enemyProjectileList) > 0 {
-						for j := 0; j < len(game.levelThreeEnemyList[i].enemyProjectileList); j++ {
-							if game.levelThreeEnemyList[i].enemyProjectileList[j].collision == false {
-								game.drawOps.GeoM.Reset()
-								game.drawOps.GeoM.Translate(float64(game.levelThreeEnemyList[i].enemyProjectileList[j].xLoc),
-									float64(game.levelThreeEnemyList[i].enemyProjectileList[j].yLoc))
-								screen.DrawImage(game.levelThreeEnemyList[i].enemyProjectileList[j].upPict, &game.drawOps)
-							}
-						}
+			for i := 0; i < len(game.enemyProjectiles.live); i++ {
+				if game.enemyProjectiles.live[i].collision == false {
+					game.drawOps.GeoM.Reset()
+					game.drawOps.GeoM.Translate(float64(game.enemyProjectiles.live[i].xLoc),
+						float64(game.enemyProjectiles.live[i].yLoc))
+					screen.DrawImage(game.enemyProjectiles.live[i].upPict, &game.drawOps)
+				}
+			}
+		} else if game.generatedRoomIsActive {
+			game.drawOps.GeoM.Reset()
+			screen.DrawImage(game.generatedMap.upPict, &game.drawOps)
+
+			game.drawOps.GeoM.Reset()
+			text.Draw(screen, "Score: "+strconv.Itoa(game.score), mplusNormalFont, ScreenWidth*0.77, ScreenHeight*0.08, colornames.White)
+
+			for i := 0; i < len(game.generatedEnemyList); i++ {
+				if game.generatedEnemyList[i].collision == false {
+					game.drawOps.GeoM.Reset()
+					game.drawOps.GeoM.Translate(float64(game.generatedEnemyList[i].xLoc), float64(game.generatedEnemyList[i].yLoc))
+					if game.generatedEnemyList[i].direction == "left" {
+						screen.DrawImage(game.generatedEnemyList[i].leftPict, &game.drawOps)
+					} else if game.generatedEnemyList[i].direction == "right" {
+						screen.DrawImage(game.generatedEnemyList[i].rightPict, &game.drawOps)
+					} else if game.generatedEnemyList[i].direction == "down" {
+						screen.DrawImage(game.generatedEnemyList[i].downPict, &game.drawOps)
+					} else {
+						screen.DrawImage(game.generatedEnemyList[i].upPict, &game.drawOps)
 					}
 				}
 			}
+
+			for i := 0; i < len(game.enemyProjectiles.live); i++ {
+				if game.enemyProjectiles.live[i].collision == false {
+					game.drawOps.GeoM.Reset()
+					game.drawOps.GeoM.Translate(float64(game.enemyProjectiles.live[i].xLoc),
+						float64(game.enemyProjectiles.live[i].yLoc))
+					screen.DrawImage(game.enemyProjectiles.live[i].upPict, &game.drawOps)
+				}
+			}
+		}
+
+		//doorways are cut out of the outer wall by drawing the floor just inside them over it
+		mapPict := game.firstMap.upPict
+		if game.levelTwoIsActive {
+			mapPict = game.secondMap.upPict
+		} else if game.levelThreeIsActive {
+			mapPict = game.thirdMap.upPict
+		} else if game.generatedRoomIsActive {
+			mapPict = game.generatedMap.upPict
+		}
+		doors := game.openDoors()
+		for i := 0; i < len(doors); i++ {
+			opening := doors[i].opening()
+			floor := opening
+			if doors[i].side == "north" {
+				floor = opening.Add(image.Pt(0, opening.Dy()))
+			} else if doors[i].side == "south" {
+				floor = opening.Sub(image.Pt(0, opening.Dy()))
+			} else if doors[i].side == "west" {
+				floor = opening.Add(image.Pt(opening.Dx(), 0))
+			} else {
+				floor = opening.Sub(image.Pt(opening.Dx(), 0))
+			}
+			game.drawOps.GeoM.Reset()
+			game.drawOps.GeoM.Translate(float64(opening.Min.X), float64(opening.Min.Y))
+			screen.DrawImage(mapPict.SubImage(floor).(*ebiten.Image), &game.drawOps)
+		}
+
+		objectives := game.currentRoom().objectives
+		for i := 0; i < len(objectives); i++ {
+			if objectives[i].kind == "exit" {
+				exit := objectives[i].exit
+				ebitenutil.DrawRect(screen, float64(exit.Min.X), float64(exit.Min.Y), float64(exit.Dx()), float64(exit.Dy()), color.RGBA{0x00, 0xc0, 0x40, 0x80})
+				text.Draw(screen, "EXIT", mplusNormalFont, exit.Min.X+10, exit.Min.Y+exit.Dy()/2+8, colornames.White)
+			}
+		}
+		text.Draw(screen, game.objectiveText(), mplusNormalFont, ScreenWidth*0.05, ScreenHeight*0.08, colornames.White)
+		timerText := "Room " + clockText(game.levelTicks) + "  Run " + clockText(game.runTicks)
+		if best, found := game.bestSplits[game.currentLevel()]; found == true && game.generatedRoomIsActive == false {
+			timerText += "  Best " + clockText(best)
+		}
+		text.Draw(screen, timerText, mplusNormalFont, ScreenWidth*0.05, ScreenHeight*0.12, colornames.White)
+
+		if game.combo > 0 {
+			//the bar under the combo shows how long is left to keep it going
+			comboText := "Combo " + strconv.Itoa(game.combo) + "  x" + strconv.Itoa(game.comboMultiplier())
+			text.Draw(screen, comboText, mplusNormalFont, ScreenWidth*0.77, ScreenHeight*0.12, colornames.Gold)
+			barWidth := 140 * float64(game.comboTicks) / comboWindow
+			ebitenutil.DrawRect(screen, ScreenWidth*0.77, ScreenHeight*0.13, barWidth, 6, colornames.Gold)
 		}
 
-		if len(game.projectileList) > 0 {
-			for i := 0; i < len(game.projectileList); i++ {
-				if game.projectileList[i].collision == false {
+		for i := 0; i < len(game.blockList); i++ {
+			if game.blockList[i].health > 0 {
+				game.drawOps.GeoM.Reset()
+				game.drawOps.GeoM.Translate(float64(game.blockList[i].xLoc), float64(game.blockList[i].yLoc))
+				screen.DrawImage(game.blockList[i].damagePicts[game.blockList[i].health-1], &game.drawOps)
+			}
+		}
+
+		if len(game.playerProjectiles.live) > 0 {
+			for i := 0; i < len(game.playerProjectiles.live); i++ {
+				if game.playerProjectiles.live[i].collision == false {
 					game.drawOps.GeoM.Reset()
-					game.drawOps.GeoM.Translate(float64(game.projectileList[i].xLoc), float64(game.projectileList[i].yLoc))
-					screen.DrawImage(game.projectileList[i].upPict, &game.drawOps)
+					game.drawOps.GeoM.Translate(float64(game.playerProjectiles.live[i].xLoc), float64(game.playerProjectiles.live[i].yLoc))
+					screen.DrawImage(game.playerProjectiles.live[i].upPict, &game.drawOps)
 				}
 			}
 		}
+
+		for i := 0; i < len(game.impactList); i++ {
+			//impact flash swells and fades out over its lifetime
+			impactWidth, impactHeight := game.impactList[i].upPict.Size()
+			progress := 1 - float64(game.impactList[i].health)/impactTicks
+			impactOps := ebiten.DrawImageOptions{}
+			impactOps.GeoM.Translate(-float64(impactWidth)/2, -float64(impactHeight)/2)
+			impactOps.GeoM.Scale(1+progress*1.5, 1+progress*1.5)
+			impactOps.GeoM.Translate(float64(game.impactList[i].xLoc), float64(game.impactList[i].yLoc))
+			impactOps.ColorM.Scale(1, 1, 1, 1-progress)
+			screen.DrawImage(game.impactList[i].upPict, &impactOps)
+		}
 		game.drawOps.GeoM.Reset()
 		game.drawOps.GeoM.Translate(float64(game.playerSprite.xLoc), float64(game.playerSprite.yLoc))
-		if game.mostRecentKeyUp == true {
+		if game.tankControls == true {
+			//rotate the up facing hull around its center to match the driving angle
+			playerWidth, playerHeight := game.playerSprite.upPict.Size()
+			game.drawOps.GeoM.Reset()
+			game.drawOps.GeoM.Translate(-float64(playerWidth)/2, -float64(playerHeight)/2)
+			game.drawOps.GeoM.Rotate(game.playerSprite.angle + math.Pi/2)
+			game.drawOps.GeoM.Translate(game.tankCenter())
+			screen.DrawImage(game.playerSprite.upPict, &game.drawOps)
+		} else if game.mostRecentKeyUp == true {
 			screen.DrawImage(game.playerSprite.upPict, &game.drawOps)
 		} else if game.mostRecentKeyDown == true {
 			screen.DrawImage(game.playerSprite.downPict, &game.drawOps)
@@ -2329,7 +3977,16 @@ func (game Game) Draw(screen *ebiten.Image) {
 
 		game.drawOps.GeoM.Reset()
 		game.drawOps.GeoM.Translate(float64(game.tankTopper.xLoc), float64(game.tankTopper.yLoc))
-		if game.mostRecentKeyW == true {
+		if game.mouseAim == true {
+			//rotate the up facing turret around its base, which sits on the center of the tank
+			topperWidth, topperHeight := game.tankTopper.upPict.Size()
+			centerX, centerY := game.tankCenter()
+			game.drawOps.GeoM.Reset()
+			game.drawOps.GeoM.Translate(-float64(topperWidth)/2, -float64(topperHeight-10))
+			game.drawOps.GeoM.Rotate(game.turretAngle + math.Pi/2)
+			game.drawOps.GeoM.Translate(centerX, centerY)
+			screen.DrawImage(game.tankTopper.upPict, &game.drawOps)
+		} else if game.mostRecentKeyW == true {
 			screen.DrawImage(game.tankTopper.upPict, &game.drawOps)
 		} else if game.mostRecentKeyS == true {
 			screen.DrawImage(game.tankTopper.downPict, &game.drawOps)
@@ -2341,7 +3998,7 @@ func (game Game) Draw(screen *ebiten.Image) {
 			screen.DrawImage(game.tankTopper.upPict, &game.drawOps)
 		}
 
-		if game.deathCounter == 0 {
+		if game.deathCounter <= 0 {
 			game.drawOps.GeoM.Reset()
 			game.drawOps.GeoM.Translate(float64(game.heartSprite1.xLoc), float64(game.heartSprite1.yLoc))
 			screen.DrawImage(game.heartSprite1.upPict, &game.drawOps)
@@ -2365,109 +4022,226 @@ func (game Game) Draw(screen *ebiten.Image) {
 		} else if game.deathCounter > 2 {
 			game.gameOver = true
 		}
-
-		if game.collectedGold == false {
+		//lives beyond the starting 3 carry on the row of hearts
+		for i := 1; i <= -game.deathCounter; i++ {
 			game.drawOps.GeoM.Reset()
-			game.drawOps.GeoM.Translate(float64(game.coinSprite.xLoc), float64(game.coinSprite.yLoc))
-			screen.DrawImage(game.coinSprite.upPict, &game.drawOps)
+			game.drawOps.GeoM.Translate(float64(game.heartSprite3.xLoc+i*(game.heartSprite2.xLoc-game.heartSprite1.xLoc)), float64(game.heartSprite3.yLoc))
+			screen.DrawImage(game.heartSprite3.upPict, &game.drawOps)
 		}
-	} else if game.startGame == true && game.gameOver == true && game.gameWon == false && game.processedDB == true {
-		tempHeight := 150
-		game.drawOps.GeoM.Reset()
-		game.drawOps.GeoM.Translate(float64(game.loserScreen.xLoc), float64(game.loserScreen.yLoc))
-		screen.DrawImage(game.loserScreen.upPict, &game.drawOps)
-		game.drawOps.GeoM.Reset()
-		text.Draw(screen, "LEADERBOARD", mplusNormalFont, ScreenWidth*0.40, ScreenHeight*0.08, colornames.White)
-		if game.allScores == true && game.playerScores == false {
-			game.drawOps.GeoM.Reset()
-			text.Draw(screen, "Press SPACE to switch to your top 5 scores.", mplusNormalFont, ScreenWidth*0.17, ScreenHeight*0.90, colornames.White)
-			if len(userNameMap) > 0 {
-				for i := 0; i < len(userNameMap) && i < 5; i++ {
-					if (game.currentPlayerAndScoreLeaderboard == false) && (userNameMap[i][0] ==
-						game.userName) && (scoreMap[i][0] == game.score) {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+userNameMap[i][0]+": "+strconv.Itoa(scoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.Red)
-						tempHeight += 100
-						game.currentPlayerAndScoreLeaderboard = true
-					} else {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+userNameMap[i][0]+": "+strconv.Itoa(scoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.White)
-						tempHeight += 100
-					}
-				}
+
+		if game.bannerTicks > 0 && (game.bannerTicks/10)%2 == 0 {
+			text.Draw(screen, game.bannerText, mplusNormalFont, ScreenWidth/2-len(game.bannerText)*6, ScreenHeight*0.45, colornames.Gold)
+		}
+
+		for i := 0; i < len(game.collectibles); i++ {
+			if game.collectibles[i].collected == false {
+				game.drawOps.GeoM.Reset()
+				game.drawOps.GeoM.Translate(float64(game.collectibles[i].sprite.xLoc), float64(game.collectibles[i].sprite.yLoc))
+				screen.DrawImage(game.collectibles[i].sprite.upPict, &game.drawOps)
 			}
-		} else if game.playerScores == true && game.allScores == false {
-			game.drawOps.GeoM.Reset()
-			text.Draw(screen, "Press SPACE to switch to all players top 5 scores.", mplusNormalFont, ScreenWidth*0.15, ScreenHeight*0.90, colornames.White)
-			if len(currentPlayerMap) > 0 {
-				for i := 0; i < len(currentPlayerMap) && i < 5; i++ {
-					if (game.currentPlayerAndScoreLeaderboard == false) && (currentPlayerMap[i][0] ==
-						game.userName) && (currentPlayerScoreMap[i][0] == game.score) {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+currentPlayerMap[i][0]+": "+strconv.Itoa(currentPlayerScoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.Red)
-						tempHeight += 100
-						game.currentPlayerAndScoreLeaderboard = true
+		}
 
-					} else {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+currentPlayerMap[i][0]+": "+strconv.Itoa(currentPlayerScoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.White)
-						tempHeight += 100
-					}
-				}
+		if game.showingResults == true {
+			ebitenutil.DrawRect(screen, 0, 0, ScreenWidth, ScreenHeight, color.RGBA{0x00, 0x00, 0x00, 0xc0})
+			text.Draw(screen, "ROOM COMPLETE", mplusNormalFont, ScreenWidth*0.38, ScreenHeight*0.25, colornames.White)
+			total := 0
+			lineHeight := ScreenHeight * 0.35
+			for i := 0; i < len(game.results); i++ {
+				shown := game.shownBonus(i)
+				total += shown
+				text.Draw(screen, game.results[i].name, mplusNormalFont, ScreenWidth*0.25, int(lineHeight), colornames.White)
+				text.Draw(screen, strconv.Itoa(shown), mplusNormalFont, ScreenWidth*0.65, int(lineHeight), colornames.White)
+				lineHeight += ScreenHeight * 0.07
+			}
+			text.Draw(screen, "Total bonus", mplusNormalFont, ScreenWidth*0.25, int(lineHeight), colornames.Gold)
+			text.Draw(screen, strconv.Itoa(total), mplusNormalFont, ScreenWidth*0.65, int(lineHeight), colornames.Gold)
+			lineHeight += ScreenHeight * 0.07
+			text.Draw(screen, "Run time "+clockText(game.runTicks), mplusNormalFont, ScreenWidth*0.25, int(lineHeight), colornames.White)
+			if game.resultsFinished() {
+				text.Draw(screen, "Press ENTER to continue.", mplusNormalFont, ScreenWidth*0.33, ScreenHeight*0.88, colornames.White)
 			}
 		}
-	} else if game.startGame == true && game.gameOver == false && game.gameWon == true && game.processedDB == true {
-		tempHeight := 150
+	} else if game.startGame == true && game.statsDismissed == false && game.processedDB == true {
+		background := game.loserScreen
+		if game.gameWon == true {
+			background = game.winnerScreen
+		}
+		game.drawOps.GeoM.Reset()
+		game.drawOps.GeoM.Translate(float64(background.xLoc), float64(background.yLoc))
+		screen.DrawImage(background.upPict, &game.drawOps)
+		text.Draw(screen, "GAME STATS", mplusNormalFont, ScreenWidth*0.40, ScreenHeight*0.08, colornames.White)
+		tempHeight := 130
+		lines := game.statsLines()
+		for i := 0; i < len(lines) && tempHeight < ScreenHeight*0.90; i++ {
+			text.Draw(screen, lines[i], mplusNormalFont, ScreenWidth*0.10, tempHeight, colornames.White)
+			tempHeight += 45
+		}
+		text.Draw(screen, "Press ENTER to see the leaderboard.", mplusNormalFont, ScreenWidth*0.22, ScreenHeight*0.96, colornames.White)
+	} else if game.startGame == true && game.showAchievements == true && game.processedDB == true {
+		background := game.loserScreen
+		if game.gameWon == true {
+			background = game.winnerScreen
+		}
+		game.drawOps.GeoM.Reset()
+		game.drawOps.GeoM.Translate(float64(background.xLoc), float64(background.yLoc))
+		screen.DrawImage(background.upPict, &game.drawOps)
+		text.Draw(screen, "ACHIEVEMENTS", mplusNormalFont, ScreenWidth*0.39, ScreenHeight*0.08, colornames.White)
+		tempHeight := 130
+		for i := 0; i < len(achievements); i++ {
+			if game.unlocked[achievements[i].id] == true {
+				text.Draw(screen, "[x] "+achievements[i].name, mplusNormalFont, ScreenWidth*0.10, tempHeight, colornames.Gold)
+			} else {
+				text.Draw(screen, "[ ] "+achievements[i].name, mplusNormalFont, ScreenWidth*0.10, tempHeight, colornames.White)
+			}
+			text.Draw(screen, achievements[i].description, mplusNormalFont, ScreenWidth*0.15, tempHeight+30, colornames.Lightgray)
+			tempHeight += 80
+		}
+		text.Draw(screen, "Press A to go back to the leaderboard.", mplusNormalFont, ScreenWidth*0.20, ScreenHeight*0.96, colornames.White)
+	} else if game.startGame == true && game.gameOver == true && game.gameWon == false && game.processedDB == true {
 		game.drawOps.GeoM.Reset()
+		game.drawOps.GeoM.Translate(float64(game.loserScreen.xLoc), float64(game.loserScreen.yLoc))
+		screen.DrawImage(game.loserScreen.upPict, &game.drawOps)
+		game.drawLeaderboard(screen)
+	} else if game.startGame == true && game.gameOver == false && game.gameWon == true && game.processedDB == true {
 		game.drawOps.GeoM.Reset()
 		game.drawOps.GeoM.Translate(float64(game.winnerScreen.xLoc), float64(game.winnerScreen.yLoc))
 		screen.DrawImage(game.winnerScreen.upPict, &game.drawOps)
-		game.drawOps.GeoM.Reset()
-		text.Draw(screen, "LEADERBOARD", mplusNormalFont, ScreenWidth*0.40, ScreenHeight*0.08, colornames.White)
-		if game.allScores == true && game.playerScores == false {
-			game.drawOps.GeoM.Reset()
-			text.Draw(screen, "Press SPACE to switch to your top 5 scores.", mplusNormalFont, ScreenWidth*0.17, ScreenHeight*0.90, colornames.White)
-			if len(userNameMap) > 0 {
-				for i := 0; i < len(userNameMap) && i < 5; i++ {
-					if (game.currentPlayerAndScoreLeaderboard == false) && (userNameMap[i][0] ==
-						game.userName) && (scoreMap[i][0] == game.score) {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+userNameMap[i][0]+": "+strconv.Itoa(scoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.Red)
-						tempHeight += 100
-						game.currentPlayerAndScoreLeaderboard = true
-					} else {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+userNameMap[i][0]+": "+strconv.Itoa(scoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.White)
-						tempHeight += 100
-					}
-				}
-			}
-		} else if game.playerScores == true && game.allScores == false {
-			game.drawOps.GeoM.Reset()
-			text.Draw(screen, "Press SPACE to switch to all players top 5 scores.", mplusNormalFont, ScreenWidth*0.15, ScreenHeight*0.90, colornames.White)
-			if len(currentPlayerMap) > 0 {
-				for i := 0; i < len(currentPlayerMap) && i < 5; i++ {
-					if (game.currentPlayerAndScoreLeaderboard == false) && (currentPlayerMap[i][0] ==
-						game.userName) && (currentPlayerScoreMap[i][0] == game.score) {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+currentPlayerMap[i][0]+": "+strconv.Itoa(currentPlayerScoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.Red)
-						tempHeight += 100
-						game.currentPlayerAndScoreLeaderboard = true
-					} else {
-						game.drawOps.GeoM.Reset()
-						text.Draw(screen, strconv.Itoa(i+1)+". "+currentPlayerMap[i][0]+": "+strconv.Itoa(currentPlayerScoreMap[i][0]), mplusNormalFont, ScreenWidth*0.15, tempHeight, colornames.White)
-						tempHeight += 100
-					}
-				}
-			}
+		game.drawLeaderboard(screen)
+	}
+
+	if len(game.toasts) > 0 {
+		toastWidth := float64(len(game.toasts[0])*12 + 40)
+		ebitenutil.DrawRect(screen, (ScreenWidth-toastWidth)/2, ScreenHeight*0.16, toastWidth, 44, color.RGBA{0x20, 0x20, 0x20, 0xe0})
+		text.Draw(screen, game.toasts[0], mplusNormalFont, int((ScreenWidth-toastWidth)/2)+20, ScreenHeight*0.16+31, colornames.Gold)
+	}
+}
+
+// drawLeaderboard draws the leaderboard tabs, the filters and the top 5 scores that match them. The
+// first entry that is the game just played is shown in red.
+func (game *Game) drawLeaderboard(screen *ebiten.Image) {
+	text.Draw(screen, "LEADERBOARD", mplusNormalFont, ScreenWidth*0.40, ScreenHeight*0.08, colornames.White)
+	tabX := []int{ScreenWidth * 0.15, ScreenWidth * 0.40, ScreenWidth * 0.68}
+	for i := 0; i < len(leaderboardTabs); i++ {
+		if i == game.leaderboardTab {
+			text.Draw(screen, "["+leaderboardTabs[i]+"]", mplusNormalFont, tabX[i], ScreenHeight*0.15, colornames.Gold)
+		} else {
+			text.Draw(screen, " "+leaderboardTabs[i], mplusNormalFont, tabX[i], ScreenHeight*0.15, colornames.White)
+		}
+	}
+	showing := "everyone"
+	if game.playerScores == true {
+		showing = "you"
+	}
+	filterText := "Difficulty: " + difficultyFilters[game.difficultyFilter] + "   Mode: " + modeFilters[game.modeFilter] +
+		"   Scores: " + showing
+	text.Draw(screen, filterText, mplusNormalFont, ScreenWidth*0.10, ScreenHeight*0.21, colornames.White)
+
+	tempHeight := 210
+	highlighted := false
+	for i := 0; i < len(userNameMap) && i < 5; i++ {
+		lineColor := colornames.White
+		if highlighted == false && userNameMap[i][0] == game.userName && scoreMap[i][0] == game.score {
+			lineColor = colornames.Red
+			highlighted = true
 		}
+		text.Draw(screen, leaderboardLine(i, userNameMap[i][0], scoreMap[i][0], comboMap[i][0], continuedMap[i][0]), mplusNormalFont, ScreenWidth*0.10, tempHeight, lineColor)
+		tempHeight += 80
+	}
+	if len(userNameMap) == 0 {
+		text.Draw(screen, "No scores yet.", mplusNormalFont, ScreenWidth*0.10, tempHeight, colornames.White)
+	}
+	text.Draw(screen, "LEFT/RIGHT: time  D: difficulty  M: mode  P: you/all", mplusNormalFont, ScreenWidth*0.08, ScreenHeight*0.90, colornames.White)
+	text.Draw(screen, "Press A to see your achievements.", mplusNormalFont, ScreenWidth*0.24, ScreenHeight*0.96, colornames.White)
+}
+
+// leaderboardLine is how one entry of the leaderboard is written. Runs started from a continue are
+// marked, since they didn't start from room 1.
+func leaderboardLine(i int, userName string, score int, maxCombo int, continued bool) string {
+	line := strconv.Itoa(i+1) + ". " + userName + ": " + strconv.Itoa(score) + "  (max combo " + strconv.Itoa(maxCombo) + ")"
+	if continued == true {
+		line += "  continued"
 	}
+	return line
 }
 
 func (g Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
 	return ScreenWidth, ScreenHeight
 }
 
+// dbPath is the leaderboard database used everywhere in the game, resolved once at startup.
+var dbPath string
+
+const dbPathEnv = "BERSERK_TANK_DB"
+
+// resolveDBPath picks where the leaderboard lives: the --db flag, then the BERSERK_TANK_DB
+// environment variable, then LeaderBoard.db in the user's XDG data directory ($XDG_DATA_HOME, or
+// ~/.local/share). The directory it goes in is created if it doesn't exist yet.
+func resolveDBPath(flagValue string) string {
+	path := flagValue
+	if path == "" {
+		path = os.Getenv(dbPathEnv)
+	}
+	usingDefault := path == ""
+	if usingDefault == true {
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				log.Fatal("can't find a place for the leaderboard, use --db: ", err)
+			}
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		path = filepath.Join(dataHome, "berserk-tank-game", "LeaderBoard.db")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatal("failed to create the leaderboard directory: ", err)
+	}
+	if usingDefault == true {
+		if err := adoptLegacyDB(path); err != nil {
+			log.Println("couldn't copy the old leaderboard, it is still at "+legacyDBPath+": ", err)
+		}
+	}
+	return path
+}
+
+// legacyDBPath is where older versions of the game kept the leaderboard, relative to the folder the
+// game was started from.
+const legacyDBPath = "LeaderBoard.db"
+
+// adoptLegacyDB copies an old leaderboard from the current folder to path the first time the game runs
+// with the default location, so existing scores carry over. The old file is left where it was.
+func adoptLegacyDB(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) == false {
+		return nil
+	}
+	legacy, err := os.Open(legacyDBPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer legacy.Close()
+	adopted, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(adopted, legacy)
+	if closeErr := adopted.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	legacyAbs, _ := filepath.Abs(legacyDBPath)
+	log.Println("copied the old leaderboard from " + legacyAbs + " to " + path)
+	return nil
+}
+
 func OpenDataBase(dbfile string) *sql.DB {
 	database, err := sql.Open("sqlite3", dbfile)
 	if err != nil {
@@ -2476,52 +4250,404 @@ func OpenDataBase(dbfile string) *sql.DB {
 	return database
 }
 
+// migration upgrades the leaderboard database from the version before it to version.
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *sql.Tx) error
+}
+
+// migrations are run in order on any LeaderBoard.db older than the last one. Never change a
+// migration once it has been released, add a new one instead.
+var migrations = []migration{
+	{1, "original leaderboard", func(tx *sql.Tx) error {
+		_, err := tx.Exec("CREATE TABLE IF NOT EXISTS players(user_name TEXT NOT NULL, score INTEGER DEFAULT 0);")
+		return err
+	}},
+	{2, "max combo and continued runs", func(tx *sql.Tx) error {
+		//databases from before versioning may have gained these columns already
+		for _, column := range []string{"max_combo", "continued"} {
+			found, err := hasColumn(tx, "players", column)
+			if err != nil {
+				return err
+			}
+			if found == false {
+				if _, err := tx.Exec("ALTER TABLE players ADD COLUMN " + column + " INTEGER DEFAULT 0;"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}},
+	{3, "best splits, achievements and campaign saves", func(tx *sql.Tx) error {
+		createStatements := []string{
+			"CREATE TABLE IF NOT EXISTS best_splits(    " +
+				"user_name TEXT NOT NULL," +
+				"level INTEGER NOT NULL," +
+				"ticks INTEGER NOT NULL," +
+				"UNIQUE(user_name, level));",
+			"CREATE TABLE IF NOT EXISTS achievements(    " +
+				"user_name TEXT NOT NULL," +
+				"achievement TEXT NOT NULL," +
+				"unlocked_at TEXT DEFAULT CURRENT_TIMESTAMP," +
+				"UNIQUE(user_name, achievement));",
+			"CREATE TABLE IF NOT EXISTS campaign(    " +
+				"user_name TEXT PRIMARY KEY," +
+				"level INTEGER NOT NULL," +
+				"lives INTEGER NOT NULL," +
+				"score INTEGER NOT NULL," +
+				"entrance_side TEXT NOT NULL," +
+				"entrance_from INTEGER NOT NULL," +
+				"entrance_to INTEGER NOT NULL);",
+		}
+		for _, statement := range createStatements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{4, "ids, play dates, level reached, duration, difficulty and a score index", func(tx *sql.Tx) error {
+		//sqlite can't add a primary key to a table, so players is copied into a new table
+		statements := []string{
+			"CREATE TABLE players_new(    " +
+				"id INTEGER PRIMARY KEY AUTOINCREMENT," +
+				"user_name TEXT NOT NULL," +
+				"score INTEGER DEFAULT 0," +
+				"max_combo INTEGER DEFAULT 0," +
+				"continued INTEGER DEFAULT 0," +
+				"played_at TEXT DEFAULT CURRENT_TIMESTAMP," +
+				"level_reached INTEGER DEFAULT 0," +
+				"duration_ticks INTEGER DEFAULT 0," +
+				"difficulty TEXT DEFAULT 'normal');",
+			//games saved before this version have no play date
+			"INSERT INTO players_new (user_name, score, max_combo, continued, played_at) " +
+				"SELECT user_name, score, max_combo, continued, NULL FROM players;",
+			"DROP TABLE players;",
+			"ALTER TABLE players_new RENAME TO players;",
+			"CREATE INDEX players_score ON players(score DESC);",
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{5, "per game stats and room times", func(tx *sql.Tx) error {
+		statements := []string{
+			"CREATE TABLE run_stats(    " +
+				"player_id INTEGER PRIMARY KEY REFERENCES players(id)," +
+				"person_kills INTEGER DEFAULT 0," +
+				"monster_kills INTEGER DEFAULT 0," +
+				"shots_fired INTEGER DEFAULT 0," +
+				"hits INTEGER DEFAULT 0," +
+				"accuracy INTEGER DEFAULT 0," +
+				"gold_collected INTEGER DEFAULT 0," +
+				"wall_deaths INTEGER DEFAULT 0," +
+				"shell_deaths INTEGER DEFAULT 0," +
+				"contact_deaths INTEGER DEFAULT 0," +
+				"max_combo INTEGER DEFAULT 0);",
+			"CREATE TABLE level_times(    " +
+				"player_id INTEGER NOT NULL REFERENCES players(id)," +
+				"level INTEGER NOT NULL," +
+				"ticks INTEGER NOT NULL);",
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{6, "game mode and a play date index", func(tx *sql.Tx) error {
+		found, err := hasColumn(tx, "players", "mode")
+		if err != nil {
+			return err
+		}
+		if found == false {
+			if _, err := tx.Exec("ALTER TABLE players ADD COLUMN mode TEXT DEFAULT 'campaign';"); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec("CREATE INDEX IF NOT EXISTS players_played_at ON players(played_at);")
+		return err
+	}},
+}
+
+func hasColumn(tx *sql.Tx, table string, column string) (bool, error) {
+	rows, err := tx.Query("PRAGMA table_info(" + table + ");")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// schemaVersion returns the version of the database, 0 if it has never been migrated.
+func schemaVersion(database *sql.DB) int {
+	if _, err := database.Exec("CREATE TABLE IF NOT EXISTS schema_version(version INTEGER NOT NULL);"); err != nil {
+		log.Fatal(err)
+	}
+	var version sql.NullInt64
+	if err := database.QueryRow("SELECT MAX(version) FROM schema_version;").Scan(&version); err != nil {
+		log.Fatal(err)
+	}
+	return int(version.Int64)
+}
+
+// create_tables brings the database up to the latest schema, running each migration it is missing
+// in its own transaction so a failed upgrade leaves the database as it was.
 func create_tables(database *sql.DB) {
-	createStatement1 := "CREATE TABLE IF NOT EXISTS players(    " +
-		"user_name TEXT NOT NULL," +
-		"score INTEGER DEFAULT 0);"
-	database.Exec(createStatement1)
+	version := schemaVersion(database)
+	for _, step := range migrations {
+		if step.version <= version {
+			continue
+		}
+		tx, err := database.Begin()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := step.apply(tx); err != nil {
+			tx.Rollback()
+			log.Fatal("failed to upgrade the leaderboard to version "+strconv.Itoa(step.version)+" ("+step.description+"): ", err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_version (version) VALUES (?);", step.version); err != nil {
+			tx.Rollback()
+			log.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
-func (game Game) addGameEntry(database *sql.DB) {
-	insertStatement := "INSERT INTO PLAYERS (user_name, score) VALUES (?,?);"
+// saveCampaign stores a checkpoint for the player and reports whether the write failed. A checkpoint
+// in an earlier room than the saved one is ignored, so the save always holds the furthest room reached.
+func (game Game) saveCampaign(database *sql.DB, checkpoint campaignCheckpoint) error {
+	insertStatement := "INSERT INTO campaign (user_name, level, lives, score, entrance_side, entrance_from, entrance_to) " +
+		"VALUES (?,?,?,?,?,?,?) ON CONFLICT(user_name) DO UPDATE SET level = excluded.level, lives = excluded.lives, " +
+		"score = excluded.score, entrance_side = excluded.entrance_side, entrance_from = excluded.entrance_from, " +
+		"entrance_to = excluded.entrance_to WHERE excluded.level >= campaign.level;"
+	_, err := database.Exec(insertStatement, game.userName, checkpoint.level, checkpoint.lives, checkpoint.score,
+		checkpoint.entrance.side, checkpoint.entrance.from, checkpoint.entrance.to)
+	return err
+}
+
+// loadCampaign reads a player's checkpoint, reporting false if they don't have one.
+func loadCampaign(database *sql.DB, userName string) (campaignCheckpoint, bool) {
+	var checkpoint campaignCheckpoint
+	row := database.QueryRow("SELECT level, lives, score, entrance_side, entrance_from, entrance_to FROM campaign WHERE user_name = ?", userName)
+	err := row.Scan(&checkpoint.level, &checkpoint.lives, &checkpoint.score,
+		&checkpoint.entrance.side, &checkpoint.entrance.from, &checkpoint.entrance.to)
+	if err == sql.ErrNoRows {
+		return checkpoint, false
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	return checkpoint, true
+}
+
+func (game Game) saveAchievement(database *sql.DB, id string) {
+	insertStatement := "INSERT OR IGNORE INTO achievements (user_name, achievement) VALUES (?,?);"
 	preppedStatement, err := database.Prepare(insertStatement)
 	if err != nil {
 		log.Fatal(err)
 	}
-	preppedStatement.Exec(game.userName, game.score)
+	preppedStatement.Exec(game.userName, id)
 }
 
-func (game Game) processDBtoMaps() {
-	db, err := sql.Open("sqlite3", "./LeaderBoard.db")
+// loadAchievements reads which achievements the player has already unlocked in earlier games.
+func (game *Game) loadAchievements() {
+	game.unlocked = make(map[string]bool)
+	myDatabase := OpenDataBase(dbPath)
+	defer myDatabase.Close()
+	create_tables(myDatabase)
+	rows, err := myDatabase.Query("SELECT achievement FROM achievements WHERE user_name = ?", game.userName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Fatal(err)
+		}
+		game.unlocked[id] = true
+	}
+}
+
+// saveBestSplit stores the player's best time for a room, in ticks.
+func (game Game) saveBestSplit(database *sql.DB, level int, ticks int) {
+	insertStatement := "INSERT INTO best_splits (user_name, level, ticks) VALUES (?,?,?) " +
+		"ON CONFLICT(user_name, level) DO UPDATE SET ticks = excluded.ticks WHERE excluded.ticks < best_splits.ticks;"
+	preppedStatement, err := database.Prepare(insertStatement)
+	if err != nil {
+		log.Fatal(err)
+	}
+	preppedStatement.Exec(game.userName, level, ticks)
+}
+
+// loadBestSplits reads the player's best time for each room, so the game can show how they compare.
+func (game *Game) loadBestSplits() {
+	game.bestSplits = make(map[int]int)
+	myDatabase := OpenDataBase(dbPath)
+	defer myDatabase.Close()
+	create_tables(myDatabase)
+	rows, err := myDatabase.Query("SELECT level, ticks FROM best_splits WHERE user_name = ?", game.userName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var level, ticks int
+		if err := rows.Scan(&level, &ticks); err != nil {
+			log.Fatal(err)
+		}
+		game.bestSplits[level] = ticks
+	}
+}
+
+// addGameEntry saves the finished game to the leaderboard, along with its stats and the time each
+// room took.
+func (game Game) addGameEntry(database *sql.DB) {
+	tx, err := database.Begin()
+	if err != nil {
+		log.Fatal(err)
+	}
+	insertStatement := "INSERT INTO players (user_name, score, max_combo, continued, level_reached, duration_ticks, difficulty, mode) " +
+		"VALUES (?,?,?,?,?,?,?,?);"
+	result, err := tx.Exec(insertStatement, game.userName, game.score, game.maxCombo, game.continued, game.levelReached, game.runTicks,
+		game.difficulty(), game.mode())
+	if err != nil {
+		tx.Rollback()
+		log.Fatal(err)
+	}
+	playerID, err := result.LastInsertId()
 	if err != nil {
+		tx.Rollback()
 		log.Fatal(err)
 	}
+	statsStatement := "INSERT INTO run_stats (player_id, person_kills, monster_kills, shots_fired, hits, accuracy, gold_collected, " +
+		"wall_deaths, shell_deaths, contact_deaths, max_combo) VALUES (?,?,?,?,?,?,?,?,?,?,?);"
+	_, err = tx.Exec(statsStatement, playerID, game.killsByType["person"], game.killsByType["monster"], game.shotsFired, game.shotsHit,
+		game.accuracy(), game.goldCollected, game.deaths["wall"], game.deaths["shell"], game.deaths["contact"], game.maxCombo)
+	if err != nil {
+		tx.Rollback()
+		log.Fatal(err)
+	}
+	for i := 0; i < len(game.splits); i++ {
+		_, err = tx.Exec("INSERT INTO level_times (player_id, level, ticks) VALUES (?,?,?);", playerID, game.splitLevels[i], game.splits[i])
+		if err != nil {
+			tx.Rollback()
+			log.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// accuracy is the percentage of the player's shots this game that hit an enemy.
+func (game *Game) accuracy() int {
+	if game.shotsFired == 0 {
+		return 0
+	}
+	return 100 * game.shotsHit / game.shotsFired
+}
+
+// statsLines is the post game stats screen, one line of text per entry.
+func (game *Game) statsLines() []string {
+	lines := []string{
+		"Score: " + strconv.Itoa(game.score),
+		"Kills: " + strconv.Itoa(game.killsByType["person"]+game.killsByType["monster"]) + " (" +
+			strconv.Itoa(game.killsByType["person"]) + " person, " + strconv.Itoa(game.killsByType["monster"]) + " monster)",
+		"Shots: " + strconv.Itoa(game.shotsFired) + "  Hits: " + strconv.Itoa(game.shotsHit) + "  Accuracy: " + strconv.Itoa(game.accuracy()) + "%",
+		"Gold collected: " + strconv.Itoa(game.goldCollected),
+		"Deaths: " + strconv.Itoa(game.deaths["wall"]) + " wall, " + strconv.Itoa(game.deaths["shell"]) + " shell, " +
+			strconv.Itoa(game.deaths["contact"]) + " contact",
+		"Max combo: " + strconv.Itoa(game.maxCombo),
+		"Run time: " + clockText(game.runTicks),
+	}
+	//room times go 3 to a line
+	roomTimes := "Room times:"
+	for i := 0; i < len(game.splits); i++ {
+		if i > 0 && i%3 == 0 {
+			lines = append(lines, roomTimes)
+			roomTimes = "   "
+		}
+		roomTimes += "  " + strconv.Itoa(game.splitLevels[i]) + ": " + clockText(game.splits[i])
+	}
+	if len(game.splits) == 0 {
+		roomTimes += "  none completed"
+	}
+	return append(lines, roomTimes)
+}
+
+// processDBtoMaps loads the top 5 scores for the chosen leaderboard tab and filters. Games saved
+// before play dates were recorded only show up under all time. Today and this week are calendar
+// ranges in local time, with weeks starting on Monday; played_at is stored in UTC.
+func (game Game) processDBtoMaps() {
+	db := OpenDataBase(dbPath)
 	defer db.Close()
-	rows, err := db.Query("SELECT * FROM players ORDER BY score DESC")
+	query := "SELECT user_name, score, max_combo, continued FROM players WHERE 1 = 1"
+	var args []interface{}
+	if leaderboardTabs[game.leaderboardTab] == "Today" {
+		query += " AND played_at >= datetime('now', 'localtime', 'start of day', 'utc')"
+	} else if leaderboardTabs[game.leaderboardTab] == "This week" {
+		query += " AND played_at >= datetime('now', 'localtime', 'start of day', '-6 days', 'weekday 1', 'utc')"
+	}
+	if difficultyFilters[game.difficultyFilter] != "all" {
+		query += " AND difficulty = ?"
+		args = append(args, difficultyFilters[game.difficultyFilter])
+	}
+	if modeFilters[game.modeFilter] != "all" {
+		query += " AND mode = ?"
+		args = append(args, modeFilters[game.modeFilter])
+	}
+	if game.playerScores == true {
+		query += " AND user_name = ?"
+		args = append(args, game.userName)
+	}
+	query += " ORDER BY score DESC LIMIT 5"
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		panic(err)
 	}
 
+	for row_number := range userNameMap {
+		delete(userNameMap, row_number)
+		delete(scoreMap, row_number)
+		delete(comboMap, row_number)
+		delete(continuedMap, row_number)
+	}
 	var temp_user_name string
 	var temp_score int
+	var temp_max_combo int
+	var temp_continued bool
 	row_number := 0
-	current_player_row_number := 0
 
 	for rows.Next() {
-		err = rows.Scan(&temp_user_name, &temp_score)
+		err = rows.Scan(&temp_user_name, &temp_score, &temp_max_combo, &temp_continued)
 		userNameMap[row_number] = append(userNameMap[row_number], temp_user_name)
 		scoreMap[row_number] = append(scoreMap[row_number], temp_score)
-		if temp_user_name == game.userName {
-			currentPlayerMap[current_player_row_number] = append(currentPlayerMap[current_player_row_number], temp_user_name)
-			currentPlayerScoreMap[current_player_row_number] = append(currentPlayerScoreMap[current_player_row_number], temp_score)
-			current_player_row_number += 1
-		}
+		comboMap[row_number] = append(comboMap[row_number], temp_max_combo)
+		continuedMap[row_number] = append(continuedMap[row_number], temp_continued)
 		row_number += 1
 	}
 	rows.Close()
 	db.Close()
-	game.processedDB = true
 
 	if err != nil {
 		log.Fatal(err)
@@ -2529,10 +4655,23 @@ func (game Game) processDBtoMaps() {
 }
 
 func main() {
+	dbFlag := flag.String("db", "", "leaderboard database file (default $"+dbPathEnv+", or LeaderBoard.db in the XDG data directory)")
+	flag.Parse()
+	dbPath = resolveDBPath(*dbFlag)
+
 	ebiten.SetWindowSize(ScreenWidth, ScreenHeight)
 	ebiten.SetWindowTitle("Berserk/Tank Game by Trevor Wysong")
 	gameObject := Game{}
 	loadImage(&gameObject)
+	gameObject.playerProjectiles = newProjectilePool(8)
+	gameObject.enemyProjectiles = newProjectilePool(32)
+	gameObject.enemyGrid = newSpatialHash(64)
+	gameObject.enemyProjectileGrid = newSpatialHash(64)
+	gameObject.kills = make(map[string]int)
+	gameObject.killsByType = make(map[string]int)
+	gameObject.deaths = make(map[string]int)
+	gameObject.levelReached = 1
+	gameObject.endlessSeed = time.Now().UnixNano()
 
 	gameObject.tankTopper.xLoc = gameObject.playerSprite.xLoc
 	gameObject.tankTopper.yLoc = gameObject.playerSprite.yLoc
@@ -2540,11 +4679,7 @@ func main() {
 	playerWidth, _ := gameObject.playerSprite.upPict.Size()
 	gameObject.playerSprite.xLoc = playerWidth
 	gameObject.playerSprite.yLoc = ScreenHeight / 2
-
-	coinWidth, coinHeight := gameObject.coinSprite.upPict.Size()
-	rand.Seed(int64(time.Now().Second()))
-	gameObject.coinSprite.xLoc = rand.Intn(ScreenWidth - coinWidth)
-	gameObject.coinSprite.yLoc = rand.Intn(ScreenHeight - coinHeight)
+	gameObject.playerSprite.angle = -math.Pi / 2
 
 	boundaryWidth := 25
 	heartWidth, heartHeight := gameObject.heartSprite1.upPict.Size()
@@ -2597,19 +4732,19 @@ func loadImage(game *Game) {
 	}
 	game.thirdMap.upPict = thirdMap
 
-	upPlayer, _, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquare.png")
+	upPlayer, upPlayerImage, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquare.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	downPlayer, _, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareDown.png")
+	downPlayer, downPlayerImage, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareDown.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	leftPlayer, _, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareLeft.png")
+	leftPlayer, leftPlayerImage, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareLeft.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	rightPlayer, _, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareRight.png")
+	rightPlayer, rightPlayerImage, err := ebitenutil.NewImageFromFile("art assets/tankFilledTopSquareRight.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
@@ -2617,6 +4752,12 @@ func loadImage(game *Game) {
 	game.playerSprite.downPict = downPlayer
 	game.playerSprite.leftPict = leftPlayer
 	game.playerSprite.rightPict = rightPlayer
+	game.playerSprite.hitbox = image.Rect(0, 0, 61, 61)
+	game.playerSprite.canUseDoors = true
+	game.playerSprite.upMask = newAlphaMask(upPlayerImage)
+	game.playerSprite.downMask = newAlphaMask(downPlayerImage)
+	game.playerSprite.leftMask = newAlphaMask(leftPlayerImage)
+	game.playerSprite.rightMask = newAlphaMask(rightPlayerImage)
 
 	tankTopperUp, _, err := ebitenutil.NewImageFromFile("art assets/tankTopper.png")
 	if err != nil {
@@ -2639,31 +4780,59 @@ func loadImage(game *Game) {
 	game.tankTopper.leftPict = tankTopperLeft
 	game.tankTopper.rightPict = tankTopperRight
 
-	fireball, _, err := ebitenutil.NewImageFromFile("art assets/fireball.png")
+	fireball, fireballImage, err := ebitenutil.NewImageFromFile("art assets/fireball.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
 	game.fireball.upPict = fireball
+	game.fireball.hitbox = image.Rect(1, 1, 19, 19)
+	game.fireball.upMask = newAlphaMask(fireballImage)
+	game.fireball.shooter = -1
+
+	//heavy fireballs are a purple version of the fireball that can't be shot down
+	heavyFireball := ebiten.NewImage(fireball.Size())
+	heavyOps := ebiten.DrawImageOptions{}
+	heavyOps.ColorM.Scale(0.6, 0.2, 0.8, 1)
+	heavyFireball.DrawImage(fireball, &heavyOps)
+	game.heavyFireball = game.fireball
+	game.heavyFireball.upPict = heavyFireball
+	game.heavyFireball.indestructible = true
 
 	coins, _, err := ebitenutil.NewImageFromFile("art assets/gold-coins-large.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
 	game.coinSprite.upPict = coins
+	game.coinSprite.hitbox = image.Rect(3, 14, 67, 65)
+	coinWidth, coinHeight := coins.Size()
+	for i := 0; i < len(collectibleTypes); i++ {
+		itemType := collectibleTypes[i]
+		itemPict := ebiten.NewImage(int(float64(coinWidth)*itemType.scale), int(float64(coinHeight)*itemType.scale))
+		itemOps := ebiten.DrawImageOptions{}
+		itemOps.GeoM.Scale(itemType.scale, itemType.scale)
+		itemOps.ColorM.ChangeHSV(itemType.hue, itemType.saturation, 1)
+		itemPict.DrawImage(coins, &itemOps)
+		item := game.coinSprite
+		item.upPict = itemPict
+		hitbox := game.coinSprite.hitbox
+		item.hitbox = image.Rect(int(float64(hitbox.Min.X)*itemType.scale), int(float64(hitbox.Min.Y)*itemType.scale),
+			int(float64(hitbox.Max.X)*itemType.scale), int(float64(hitbox.Max.Y)*itemType.scale))
+		game.collectibleSprites = append(game.collectibleSprites, item)
+	}
 
-	personEnemyUp, _, err := ebitenutil.NewImageFromFile("art assets/personEnemyUp.png")
+	personEnemyUp, personEnemyUpImage, err := ebitenutil.NewImageFromFile("art assets/personEnemyUp.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	personEnemyDown, _, err := ebitenutil.NewImageFromFile("art assets/personEnemyDown.png")
+	personEnemyDown, personEnemyDownImage, err := ebitenutil.NewImageFromFile("art assets/personEnemyDown.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	personEnemyLeft, _, err := ebitenutil.NewImageFromFile("art assets/personEnemyLeft.png")
+	personEnemyLeft, personEnemyLeftImage, err := ebitenutil.NewImageFromFile("art assets/personEnemyLeft.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	personEnemyRight, _, err := ebitenutil.NewImageFromFile("art assets/personEnemyRight.png")
+	personEnemyRight, personEnemyRightImage, err := ebitenutil.NewImageFromFile("art assets/personEnemyRight.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
@@ -2671,20 +4840,26 @@ func loadImage(game *Game) {
 	game.personEnemy.downPict = personEnemyDown
 	game.personEnemy.leftPict = personEnemyLeft
 	game.personEnemy.rightPict = personEnemyRight
-
-	monsterEnemyUp, _, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyUp.png")
+	game.personEnemy.hitbox = image.Rect(0, 0, 38, 64)
+	game.personEnemy.enemyType = "person"
+	game.personEnemy.upMask = newAlphaMask(personEnemyUpImage)
+	game.personEnemy.downMask = newAlphaMask(personEnemyDownImage)
+	game.personEnemy.leftMask = newAlphaMask(personEnemyLeftImage)
+	game.personEnemy.rightMask = newAlphaMask(personEnemyRightImage)
+
+	monsterEnemyUp, monsterEnemyUpImage, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyUp.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	monsterEnemyDown, _, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyDown.png")
+	monsterEnemyDown, monsterEnemyDownImage, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyDown.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	monsterEnemyLeft, _, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyLeft.png")
+	monsterEnemyLeft, monsterEnemyLeftImage, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyLeft.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
-	monsterEnemyRight, _, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyRight.png")
+	monsterEnemyRight, monsterEnemyRightImage, err := ebitenutil.NewImageFromFile("art assets/monsterEnemyRight.png")
 	if err != nil {
 		log.Fatal("failed to load image", err)
 	}
@@ -2692,6 +4867,12 @@ func loadImage(game *Game) {
 	game.monsterEnemy.downPict = monsterEnemyDown
 	game.monsterEnemy.leftPict = monsterEnemyLeft
 	game.monsterEnemy.rightPict = monsterEnemyRight
+	game.monsterEnemy.hitbox = image.Rect(1, 1, 49, 49)
+	game.monsterEnemy.enemyType = "monster"
+	game.monsterEnemy.upMask = newAlphaMask(monsterEnemyUpImage)
+	game.monsterEnemy.downMask = newAlphaMask(monsterEnemyDownImage)
+	game.monsterEnemy.leftMask = newAlphaMask(monsterEnemyLeftImage)
+	game.monsterEnemy.rightMask = newAlphaMask(monsterEnemyRightImage)
 
 	heart, _, err := ebitenutil.NewImageFromFile("art assets/heartScaled.png")
 	if err != nil {
@@ -2700,4 +4881,32 @@ func loadImage(game *Game) {
 	game.heartSprite1.upPict = heart
 	game.heartSprite2.upPict = heart
 	game.heartSprite3.upPict = heart
+
+	game.wallBlock.hitbox = image.Rect(0, 0, 50, 25)
+	game.wallBlock.damagePicts = newBlockPicts(50, 25)
+}
+
+// newBlockPicts draws the breakable block sprites, from most damaged to intact.
+// The block with health h is drawn with the picture at index h-1.
+func newBlockPicts(width, height int) []*ebiten.Image {
+	blockColor := color.RGBA{0x8b, 0x5a, 0x2b, 0xff}
+	crackColor := color.RGBA{0x2e, 0x1b, 0x0e, 0xff}
+	w, h := float64(width), float64(height)
+
+	intact := ebiten.NewImage(width, height)
+	intact.Fill(crackColor)
+	ebitenutil.DrawRect(intact, 2, 2, w-4, h-4, blockColor)
+
+	cracked := ebiten.NewImage(width, height)
+	cracked.DrawImage(intact, nil)
+	ebitenutil.DrawLine(cracked, w*0.3, 2, w*0.45, h*0.6, crackColor)
+	ebitenutil.DrawLine(cracked, w*0.45, h*0.6, w*0.4, h-2, crackColor)
+
+	broken := ebiten.NewImage(width, height)
+	broken.DrawImage(cracked, nil)
+	ebitenutil.DrawLine(broken, w*0.45, h*0.6, w*0.8, h*0.3, crackColor)
+	ebitenutil.DrawLine(broken, w*0.8, h*0.3, w-2, h*0.5, crackColor)
+	ebitenutil.DrawLine(broken, 2, h*0.4, w*0.3, h*0.5, crackColor)
+
+	return []*ebiten.Image{broken, cracked, intact}
 }
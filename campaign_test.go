@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestSaveCampaignKeepsTheFurthestRoom(t *testing.T) {
+	database := openTestDB(t)
+	create_tables(database)
+	game := Game{userName: "tank"}
+	saves := []campaignCheckpoint{
+		{level: 1, lives: 2, score: 1500, entrance: roomDoor{side: "west", from: 450, to: 560}},
+		{level: 0, lives: 3, score: 200, entrance: roomDoor{side: "east", from: 100, to: 200}},
+	}
+	for _, checkpoint := range saves {
+		if err := game.saveCampaign(database, checkpoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded, found := loadCampaign(database, "tank")
+	if found == false || loaded != saves[0] {
+		t.Fatalf("loadCampaign = %+v, %v, want %+v, true", loaded, found, saves[0])
+	}
+
+	later := campaignCheckpoint{level: 2, lives: 1, score: 3000, entrance: roomDoor{side: "north", from: 650, to: 760}}
+	if err := game.saveCampaign(database, later); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := loadCampaign(database, "tank"); loaded != later {
+		t.Fatalf("loadCampaign = %+v, want %+v", loaded, later)
+	}
+	if _, found := loadCampaign(database, "nobody"); found == true {
+		t.Fatal("found a checkpoint for a player who never saved")
+	}
+}
+
+func TestSaveCampaignReportsFailedWrites(t *testing.T) {
+	database := openTestDB(t)
+	//no tables, so the write has to fail
+	if err := (Game{userName: "tank"}).saveCampaign(database, campaignCheckpoint{}); err == nil {
+		t.Fatal("saveCampaign didn't report a failed write")
+	}
+}
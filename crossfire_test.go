@@ -0,0 +1,43 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestInLineOfFire(t *testing.T) {
+	player := image.Rect(300, 300, 361, 361)
+	tests := []struct {
+		name  string
+		shell Sprite
+		want  bool
+	}{
+		{"fired straight at the player", Sprite{xLoc: 100, yLoc: 320, dx: 3, hitbox: image.Rect(1, 1, 19, 19)}, true},
+		{"fired away from the player", Sprite{xLoc: 100, yLoc: 320, dx: -3, hitbox: image.Rect(1, 1, 19, 19)}, false},
+		{"fired down past the player's side", Sprite{xLoc: 250, yLoc: 100, dy: 3, hitbox: image.Rect(1, 1, 19, 19)}, true},
+		{"fired down well clear of the player", Sprite{xLoc: 150, yLoc: 100, dy: 3, hitbox: image.Rect(1, 1, 19, 19)}, false},
+		{"fired up at the player from below", Sprite{xLoc: 320, yLoc: 600, dy: -3, hitbox: image.Rect(1, 1, 19, 19)}, true},
+	}
+	for _, test := range tests {
+		if got := inLineOfFire(test.shell, player); got != test.want {
+			t.Errorf("%s: inLineOfFire = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecordKillKeepsCrossfireOutOfPlayerStats(t *testing.T) {
+	game := Game{kills: make(map[string]int), killsByType: make(map[string]int)}
+	enemy := Sprite{enemyType: "person"}
+	game.recordKill(&enemy, "crossfire")
+	game.recordKill(&enemy, "wall")
+	if game.combo != 0 || game.killsByType["person"] != 0 {
+		t.Fatalf("combo %d, person kills %d after crossfire and wall kills, want 0 and 0", game.combo, game.killsByType["person"])
+	}
+	if game.kills["crossfire"] != 1 || enemy.killedBy != "wall" {
+		t.Fatalf("crossfire kills %d, killed by %q", game.kills["crossfire"], enemy.killedBy)
+	}
+	game.recordKill(&enemy, "player")
+	if game.combo != 1 || game.killsByType["person"] != 1 {
+		t.Fatalf("combo %d, person kills %d after a player kill, want 1 and 1", game.combo, game.killsByType["person"])
+	}
+}
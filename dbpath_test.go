@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setEnv sets an environment variable for the length of a test.
+func setEnv(t *testing.T, key string, value string) {
+	old, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// inTempDir runs the rest of a test from a fresh folder, where a legacy LeaderBoard.db would be found.
+func inTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+	return dir
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
+
+func TestResolveDBPathFlagOverridesEnv(t *testing.T) {
+	dir := inTempDir(t)
+	setEnv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))
+	setEnv(t, dbPathEnv, filepath.Join(dir, "env", "env.db"))
+
+	flagPath := filepath.Join(dir, "flag", "flag.db")
+	if got := resolveDBPath(flagPath); got != flagPath {
+		t.Fatalf("resolveDBPath with a flag = %s, want %s", got, flagPath)
+	}
+	if got := resolveDBPath(""); got != filepath.Join(dir, "env", "env.db") {
+		t.Fatalf("resolveDBPath with only the env var = %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "flag")); err != nil {
+		t.Fatalf("the flag's folder wasn't created: %v", err)
+	}
+}
+
+func TestResolveDBPathDefaultsToDataHome(t *testing.T) {
+	dir := inTempDir(t)
+	setEnv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))
+	setEnv(t, dbPathEnv, "")
+
+	want := filepath.Join(dir, "data", "berserk-tank-game", "LeaderBoard.db")
+	if got := resolveDBPath(""); got != want {
+		t.Fatalf("resolveDBPath = %s, want %s", got, want)
+	}
+	//no legacy leaderboard, so nothing is created at the default
+	if _, err := os.Stat(want); os.IsNotExist(err) == false {
+		t.Fatalf("a leaderboard appeared at the default without a legacy one: %v", err)
+	}
+}
+
+func TestResolveDBPathCopiesLegacyOnce(t *testing.T) {
+	dir := inTempDir(t)
+	setEnv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))
+	setEnv(t, dbPathEnv, "")
+	writeFile(t, legacyDBPath, "legacy scores")
+
+	path := resolveDBPath("")
+	if readFile(t, path) != "legacy scores" {
+		t.Fatal("the legacy leaderboard wasn't copied to the default")
+	}
+	if readFile(t, legacyDBPath) != "legacy scores" {
+		t.Fatal("the legacy leaderboard was changed")
+	}
+
+	//a second run must keep the adopted copy, even if the old file changed since
+	writeFile(t, legacyDBPath, "newer legacy scores")
+	resolveDBPath("")
+	if readFile(t, path) != "legacy scores" {
+		t.Fatal("the legacy leaderboard was copied a second time")
+	}
+}
+
+func TestResolveDBPathNeverOverwritesTheDefault(t *testing.T) {
+	dir := inTempDir(t)
+	setEnv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))
+	setEnv(t, dbPathEnv, "")
+	writeFile(t, legacyDBPath, "legacy scores")
+	existing := filepath.Join(dir, "data", "berserk-tank-game", "LeaderBoard.db")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, existing, "current scores")
+
+	resolveDBPath("")
+	if readFile(t, existing) != "current scores" {
+		t.Fatal("the existing leaderboard was overwritten by the legacy one")
+	}
+}
+
+func TestResolveDBPathOnlyAdoptsForTheDefault(t *testing.T) {
+	dir := inTempDir(t)
+	setEnv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))
+	setEnv(t, dbPathEnv, "")
+	writeFile(t, legacyDBPath, "legacy scores")
+
+	flagPath := filepath.Join(dir, "flag.db")
+	resolveDBPath(flagPath)
+	if _, err := os.Stat(flagPath); os.IsNotExist(err) == false {
+		t.Fatal("the legacy leaderboard was copied to a path given with --db")
+	}
+}
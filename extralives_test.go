@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestExtraLivesFromScore(t *testing.T) {
+	defer func(saved extraLifeRules) { lifeRules = saved }(lifeRules)
+	lifeRules = extraLifeRules{scoreEvery: 5000, goldEvery: 2, maxLives: 9, fullBonus: 1000}
+
+	var game Game
+	steps := []struct {
+		score     int
+		wantLives int
+	}{
+		{4999, startingLives},
+		{5000, startingLives + 1},
+		{9999, startingLives + 1},
+		//one jump past both 10000 and 15000 earns two lives
+		{15000, startingLives + 3},
+		{19999, startingLives + 3},
+	}
+	for _, step := range steps {
+		game.score = step.score
+		game.manageExtraLives()
+		if game.lives() != step.wantLives {
+			t.Fatalf("at %d points lives = %d, want %d", step.score, game.lives(), step.wantLives)
+		}
+	}
+	if game.nextLifeScore != 20000 {
+		t.Fatalf("next life at %d points, want 20000", game.nextLifeScore)
+	}
+}
+
+func TestExtraLivesFromGold(t *testing.T) {
+	defer func(saved extraLifeRules) { lifeRules = saved }(lifeRules)
+	lifeRules = extraLifeRules{scoreEvery: 0, goldEvery: 2, maxLives: 9}
+	if collectibleTypes[0].gold == false {
+		t.Fatal("the first collectible type isn't gold")
+	}
+
+	var game Game
+	wantLives := []int{startingLives, startingLives + 1, startingLives + 1, startingLives + 2}
+	for i, want := range wantLives {
+		game.pickUp(&collectible{kind: 0})
+		if game.lives() != want {
+			t.Fatalf("after %d gold piles lives = %d, want %d", i+1, game.lives(), want)
+		}
+	}
+}
+
+func TestExtraLifeAtTheCapPaysABonus(t *testing.T) {
+	if lifeRules.maxLives <= startingLives {
+		t.Fatalf("maxLives %d leaves no room above the %d starting lives", lifeRules.maxLives, startingLives)
+	}
+	game := Game{deathCounter: startingLives - lifeRules.maxLives, score: 4990}
+	game.manageExtraLives()
+	game.score = 5000
+	game.manageExtraLives()
+	if game.lives() != lifeRules.maxLives {
+		t.Fatalf("lives = %d, want the cap of %d", game.lives(), lifeRules.maxLives)
+	}
+	if game.score != 5000+lifeRules.fullBonus || game.bannerTicks == 0 {
+		t.Fatalf("score %d and banner %d after a life at the cap, want %d and a banner", game.score, game.bannerTicks, 5000+lifeRules.fullBonus)
+	}
+}
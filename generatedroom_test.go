@@ -0,0 +1,108 @@
+package main
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// testEntrances is a door on each side of the maze for the player to come in by.
+var testEntrances = []roomDoor{
+	mazeDoor("west", image.Pt(0, 2), generatedRoomIndex),
+	mazeDoor("east", image.Pt(mazeColumns-1, 0), generatedRoomIndex),
+	mazeDoor("north", image.Pt(3, 0), generatedRoomIndex),
+	mazeDoor("south", image.Pt(5, mazeRows-1), generatedRoomIndex),
+}
+
+func TestGenerateRoomIsDeterministic(t *testing.T) {
+	for _, entrance := range testEntrances {
+		first := generateRoom(42, entrance, 6)
+		second := generateRoom(42, entrance, 6)
+		if reflect.DeepEqual(first, second) == false {
+			t.Fatalf("seed 42 entering by the %s gave two different rooms", entrance.side)
+		}
+		if reflect.DeepEqual(first.layout.walls, generateRoom(43, entrance, 6).layout.walls) == true {
+			t.Fatalf("seeds 42 and 43 entering by the %s gave the same walls", entrance.side)
+		}
+	}
+}
+
+// reachableCells walks the maze grid from start, crossing from a cell to its neighbour wherever no
+// wall lies on the line between their centers.
+func reachableCells(walls []image.Rectangle, start image.Point) map[image.Point]bool {
+	reached := map[image.Point]bool{start: true}
+	queue := []image.Point{start}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, step := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			neighbour := cell.Add(step)
+			if neighbour.X < 0 || neighbour.Y < 0 || neighbour.X >= mazeColumns || neighbour.Y >= mazeRows || reached[neighbour] {
+				continue
+			}
+			a, b := mazeCellCenter(cell), mazeCellCenter(neighbour)
+			path := image.Rect(a.X, a.Y, b.X, b.Y).Canon()
+			path.Max = path.Max.Add(image.Pt(1, 1))
+			if overlapsAny(path, walls) == false {
+				reached[neighbour] = true
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	return reached
+}
+
+func TestGeneratedRoomIsConnected(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		for _, entrance := range testEntrances {
+			generated := generateRoom(seed, entrance, 6)
+			reached := reachableCells(generated.layout.walls, doorCell(entrance))
+			if len(reached) != mazeColumns*mazeRows {
+				t.Fatalf("seed %d entering by the %s: %d of %d cells can be reached", seed, entrance.side, len(reached), mazeColumns*mazeRows)
+			}
+			if len(generated.layout.doors) == 0 {
+				t.Fatalf("seed %d entering by the %s: no exits", seed, entrance.side)
+			}
+			for _, door := range generated.layout.doors {
+				if door.side == entrance.side {
+					t.Fatalf("seed %d: an exit shares the entrance's %s wall", seed, entrance.side)
+				}
+				if reached[doorCell(door)] == false {
+					t.Fatalf("seed %d entering by the %s: the %s exit can't be reached", seed, entrance.side, door.side)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratedEnemiesStayClearOfEntry(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		for _, entrance := range testEntrances {
+			generated := generateRoom(seed, entrance, 6)
+			if len(generated.enemySpots) != 6 {
+				t.Fatalf("seed %d entering by the %s: %d enemies, want 6", seed, entrance.side, len(generated.enemySpots))
+			}
+			entry := mazeCellCenter(doorCell(entrance))
+			for _, spot := range generated.enemySpots {
+				if math.Hypot(float64(spot.X-entry.X), float64(spot.Y-entry.Y)) <= safeEntryRadius {
+					t.Fatalf("seed %d entering by the %s: enemy at %v is within %d of the entry %v", seed, entrance.side, spot, safeEntryRadius, entry)
+				}
+				if overlapsAny(image.Rect(spot.X, spot.Y, spot.X+1, spot.Y+1), generated.layout.walls) {
+					t.Fatalf("seed %d entering by the %s: enemy at %v is inside a wall", seed, entrance.side, spot)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratedRoomIsKeptOffTheRoomGraph(t *testing.T) {
+	handMade := len(rooms)
+	game := Game{generatedRoomIsActive: true, generatedLayout: generateRoom(7, testEntrances[0], 4)}
+	if reflect.DeepEqual(game.currentRoom(), game.generatedLayout.layout) == false {
+		t.Fatal("currentRoom isn't the generated room being played")
+	}
+	if len(rooms) != handMade {
+		t.Fatalf("rooms has %d rooms, want the %d hand-made ones", len(rooms), handMade)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestHitboxAtTurnsWithDirection(t *testing.T) {
+	//the person enemy's up picture and hitbox are 38 wide and 64 tall
+	person := Sprite{upPict: ebiten.NewImage(38, 64), hitbox: image.Rect(0, 0, 38, 64)}
+	tests := []struct {
+		direction string
+		want      image.Rectangle
+	}{
+		{"", image.Rect(100, 200, 138, 264)},
+		{"up", image.Rect(100, 200, 138, 264)},
+		{"down", image.Rect(100, 200, 138, 264)},
+		{"left", image.Rect(100, 200, 164, 238)},
+		{"right", image.Rect(100, 200, 164, 238)},
+	}
+	for _, test := range tests {
+		person.direction = test.direction
+		if got := hitboxAt(person, 100, 200); got != test.want {
+			t.Errorf("hitboxAt facing %q = %v, want %v", test.direction, got, test.want)
+		}
+	}
+}
+
+func TestHitboxAtMirrorsInsetBoxes(t *testing.T) {
+	//a box inset from the picture's edges lands on the matching edges once turned
+	sprite := Sprite{upPict: ebiten.NewImage(20, 40), hitbox: image.Rect(2, 4, 18, 30)}
+	tests := []struct {
+		direction string
+		want      image.Rectangle
+	}{
+		{"up", image.Rect(2, 4, 18, 30)},
+		{"down", image.Rect(2, 10, 18, 36)},
+		{"right", image.Rect(10, 2, 36, 18)},
+		{"left", image.Rect(4, 2, 30, 18)},
+	}
+	for _, test := range tests {
+		sprite.direction = test.direction
+		if got := hitboxAt(sprite, 0, 0); got != test.want {
+			t.Errorf("hitboxAt facing %q = %v, want %v", test.direction, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// openTestDB opens an in-memory leaderboard. It keeps a single connection because every new
+// connection to :memory: would get its own empty database.
+func openTestDB(t *testing.T) *sql.DB {
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// applyMigration runs one migration in its own transaction, the way create_tables does.
+func applyMigration(t *testing.T, database *sql.DB, step migration) {
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := step.apply(tx); err != nil {
+		tx.Rollback()
+		t.Fatalf("migration %d (%s): %v", step.version, step.description, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findMigration(t *testing.T, version int) migration {
+	for _, step := range migrations {
+		if step.version == version {
+			return step
+		}
+	}
+	t.Fatalf("no migration %d", version)
+	return migration{}
+}
+
+func playersHasColumn(t *testing.T, database *sql.DB, column string) bool {
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	found, err := hasColumn(tx, "players", column)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func TestMigrationsUpgradePre047Table(t *testing.T) {
+	database := openTestDB(t)
+	//before versioning, max_combo and continued were added to players with unchecked ALTER TABLEs
+	statements := []string{
+		"CREATE TABLE players(user_name TEXT NOT NULL, score INTEGER DEFAULT 0);",
+		"ALTER TABLE players ADD COLUMN max_combo INTEGER DEFAULT 0;",
+		"ALTER TABLE players ADD COLUMN continued INTEGER DEFAULT 0;",
+		"INSERT INTO players (user_name, score, max_combo, continued) VALUES ('tank', 1200, 4, 1);",
+	}
+	for _, statement := range statements {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	create_tables(database)
+	if version := schemaVersion(database); version != migrations[len(migrations)-1].version {
+		t.Fatalf("schema version = %d, want %d", version, migrations[len(migrations)-1].version)
+	}
+	if playersHasColumn(t, database, "mode") == false {
+		t.Fatal("players has no mode column after migrating")
+	}
+	var score int
+	var mode string
+	if err := database.QueryRow("SELECT score, mode FROM players WHERE user_name = 'tank';").Scan(&score, &mode); err != nil {
+		t.Fatal(err)
+	}
+	if score != 1200 || mode != "campaign" {
+		t.Fatalf("migrated row = %d %q, want 1200 \"campaign\"", score, mode)
+	}
+}
+
+func TestMigrationsRerunOnMigratedTable(t *testing.T) {
+	database := openTestDB(t)
+	create_tables(database)
+
+	//the mode migration must cope with its column and index already being there
+	modeStep := findMigration(t, 6)
+	applyMigration(t, database, modeStep)
+	applyMigration(t, database, modeStep)
+	if playersHasColumn(t, database, "mode") == false {
+		t.Fatal("players has no mode column")
+	}
+
+	//a schema_version row lost after the column was added must not stop the upgrade
+	if _, err := database.Exec("DELETE FROM schema_version WHERE version >= ?;", modeStep.version); err != nil {
+		t.Fatal(err)
+	}
+	create_tables(database)
+	if version := schemaVersion(database); version != migrations[len(migrations)-1].version {
+		t.Fatalf("schema version = %d, want %d", version, migrations[len(migrations)-1].version)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestObjectiveMet(t *testing.T) {
+	exit := image.Rect(650, 560, 760, 660)
+	tests := []struct {
+		name      string
+		game      Game
+		objective levelObjective
+		want      bool
+	}{
+		{"clear with an enemy left", Game{levelOneEnemyList: []Sprite{{collision: true}, {}}},
+			levelObjective{kind: "clear"}, false},
+		{"clear with every enemy destroyed", Game{levelOneEnemyList: []Sprite{{collision: true}, {collision: true}}},
+			levelObjective{kind: "clear"}, true},
+		{"exit away from the exit", Game{playerSprite: Sprite{xLoc: 100, yLoc: 100, hitbox: image.Rect(0, 0, 61, 61)}},
+			levelObjective{kind: "exit", exit: exit}, false},
+		{"exit inside the exit", Game{playerSprite: Sprite{xLoc: 660, yLoc: 580, hitbox: image.Rect(0, 0, 61, 61)}},
+			levelObjective{kind: "exit", exit: exit}, true},
+		{"survive before the time is up", Game{levelTicks: 2699},
+			levelObjective{kind: "survive", surviveTicks: 2700}, false},
+		{"survive once the time is up", Game{levelTicks: 2700},
+			levelObjective{kind: "survive", surviveTicks: 2700}, true},
+		{"collect short of the count", Game{itemsCollected: 2},
+			levelObjective{kind: "collect", itemCount: 3}, false},
+		{"collect with enough items", Game{itemsCollected: 3},
+			levelObjective{kind: "collect", itemCount: 3}, true},
+		{"unknown kind", Game{}, levelObjective{kind: "teleport"}, false},
+	}
+	for _, test := range tests {
+		if got := test.game.objectiveMet(test.objective); got != test.want {
+			t.Errorf("%s: objectiveMet = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCampaignRoomObjectives(t *testing.T) {
+	//every kind of objective is used by one of the hand-made rooms
+	used := make(map[string]bool)
+	for level := range rooms {
+		for _, objective := range rooms[level].objectives {
+			used[objective.kind] = true
+			if objective.kind == "exit" {
+				for _, wall := range rooms[level].walls {
+					if objective.exit.Overlaps(wall) {
+						t.Errorf("the exit of room %d overlaps a wall", level+1)
+					}
+				}
+			}
+		}
+	}
+	for _, kind := range []string{"clear", "exit", "survive", "collect"} {
+		if used[kind] == false {
+			t.Errorf("no room has a %q objective", kind)
+		}
+	}
+}
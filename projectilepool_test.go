@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestProjectilePoolCapAndPrune(t *testing.T) {
+	pool := newProjectilePool(4)
+	for i := 0; i < 4; i++ {
+		pool.fire(Sprite{xLoc: i})
+	}
+	if pool.hasRoom() == true {
+		t.Fatal("hasRoom() = true for a full pool")
+	}
+	backing := &pool.live[0]
+	pool.fire(Sprite{xLoc: 99})
+	if len(pool.live) != 4 {
+		t.Fatalf("len(live) = %d after firing into a full pool, want 4", len(pool.live))
+	}
+	for i := 0; i < len(pool.live); i++ {
+		if pool.live[i].xLoc == 99 {
+			t.Fatal("a full pool kept the extra shell")
+		}
+	}
+
+	pool.live[0].collision = true
+	pool.live[2].collision = true
+	pool.prune()
+	want := []int{1, 3}
+	if len(pool.live) != len(want) {
+		t.Fatalf("len(live) = %d after pruning, want %d", len(pool.live), len(want))
+	}
+	for i := range want {
+		if pool.live[i].xLoc != want[i] {
+			t.Fatalf("live[%d] is shell %d, want %d", i, pool.live[i].xLoc, want[i])
+		}
+	}
+	if pool.hasRoom() == false {
+		t.Fatal("hasRoom() = false after pruning")
+	}
+
+	//freed slots are reused instead of growing the slice
+	pool.fire(Sprite{xLoc: 4})
+	pool.fire(Sprite{xLoc: 5})
+	if &pool.live[0] != backing || cap(pool.live) != 4 {
+		t.Fatal("the pool reallocated its shells")
+	}
+	want = []int{1, 3, 4, 5}
+	for i := range want {
+		if pool.live[i].xLoc != want[i] {
+			t.Fatalf("live[%d] is shell %d, want %d", i, pool.live[i].xLoc, want[i])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+const (
+	benchEnemies = 300
+	benchShells  = 300
+)
+
+// pairSink keeps the benchmarked pair counts from being optimized away.
+var pairSink int
+
+// randomBoxes scatters count boxes of the given size over the play area.
+func randomBoxes(rng *rand.Rand, count int, size int) []image.Rectangle {
+	boxes := make([]image.Rectangle, count)
+	for i := range boxes {
+		x := rng.Intn(ScreenWidth - size)
+		y := rng.Intn(ScreenHeight - size)
+		boxes[i] = image.Rect(x, y, x+size, y+size)
+	}
+	return boxes
+}
+
+// bruteForceOverlaps checks every box against area, the way the collision loops did before the grid.
+func bruteForceOverlaps(boxes []image.Rectangle, area image.Rectangle) []int {
+	var found []int
+	for id, box := range boxes {
+		if box.Overlaps(area) {
+			found = append(found, id)
+		}
+	}
+	return found
+}
+
+func TestSpatialHashQueryMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	enemies := randomBoxes(rng, benchEnemies, 61)
+	shells := randomBoxes(rng, benchShells, 10)
+	//a box straddling the origin checks the negative cells
+	enemies[0] = image.Rect(-30, -30, 31, 31)
+
+	grid := newSpatialHash(64)
+	for id, box := range enemies {
+		grid.insert(id, box)
+	}
+	for _, shell := range shells {
+		want := bruteForceOverlaps(enemies, shell)
+		got := grid.query(shell)
+		if len(got) != len(want) {
+			t.Fatalf("query(%v) = %v, want %v", shell, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("query(%v) = %v, want %v", shell, got, want)
+			}
+		}
+	}
+
+	//a rebuilt grid must not return anything from before the reset
+	grid.reset()
+	grid.insert(0, enemies[1])
+	for _, shell := range shells {
+		want := bruteForceOverlaps(enemies[1:2], shell)
+		if got := grid.query(shell); len(got) != len(want) {
+			t.Fatalf("after reset query(%v) = %v, want %v", shell, got, want)
+		}
+	}
+}
+
+func BenchmarkNestedLoopPairs(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	enemies := randomBoxes(rng, benchEnemies, 61)
+	shells := randomBoxes(rng, benchShells, 10)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		pairs := 0
+		for _, shell := range shells {
+			for _, enemy := range enemies {
+				if shell.Overlaps(enemy) {
+					pairs++
+				}
+			}
+		}
+		pairSink = pairs
+	}
+}
+
+func BenchmarkSpatialHashPairs(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	enemies := randomBoxes(rng, benchEnemies, 61)
+	shells := randomBoxes(rng, benchShells, 10)
+	grid := newSpatialHash(64)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		//the game rebuilds the grid every tick, so that cost is part of each iteration
+		grid.reset()
+		for id, enemy := range enemies {
+			grid.insert(id, enemy)
+		}
+		pairs := 0
+		for _, shell := range shells {
+			pairs += len(grid.query(shell))
+		}
+		pairSink = pairs
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// cornerSprite is a 10x10 sprite at x, y whose picture is only opaque in a 3x3 square at corner.
+func cornerSprite(x int, y int, corner image.Point) Sprite {
+	picture := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for py := corner.Y; py < corner.Y+3; py++ {
+		for px := corner.X; px < corner.X+3; px++ {
+			picture.Set(px, py, color.NRGBA{0xff, 0, 0, 0xff})
+		}
+	}
+	return Sprite{xLoc: x, yLoc: y, hitbox: image.Rect(0, 0, 10, 10), upMask: newAlphaMask(picture)}
+}
+
+func TestSpritesTouch(t *testing.T) {
+	//the boxes overlap from 5 to 10 on both axes, but a's opaque corner ends at 3 and b's starts at 12
+	a := cornerSprite(0, 0, image.Pt(0, 0))
+	b := cornerSprite(5, 5, image.Pt(7, 7))
+	if spritesTouch(a, b) == false {
+		t.Error("overlapping bounding boxes don't touch")
+	}
+	a.pixelPerfect, b.pixelPerfect = true, true
+	if spritesTouch(a, b) == true {
+		t.Error("pixel perfect sprites touch where neither picture is opaque")
+	}
+	b.pixelPerfect = false
+	if spritesTouch(a, b) == true {
+		t.Error("a pixel perfect sprite touches a box outside its opaque pixels")
+	}
+	a.direction = "rotated"
+	if spritesTouch(a, b) == false {
+		t.Error("a freely turned tank doesn't fall back to its bounding box")
+	}
+
+	//opaque pixels that do overlap still touch
+	c := cornerSprite(0, 0, image.Pt(7, 7))
+	d := cornerSprite(8, 8, image.Pt(0, 0))
+	c.pixelPerfect, d.pixelPerfect = true, true
+	if spritesTouch(c, d) == false {
+		t.Error("pixel perfect sprites with overlapping opaque pixels don't touch")
+	}
+
+	far := cornerSprite(20, 20, image.Pt(0, 0))
+	if spritesTouch(a, far) == true {
+		t.Error("sprites whose boxes don't overlap touch")
+	}
+}
+
+func TestNewAlphaMask(t *testing.T) {
+	picture := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	picture.Set(0, 0, color.NRGBA{0, 0, 0, 0x7f})
+	picture.Set(1, 0, color.NRGBA{0, 0, 0, 0x80})
+	mask := newAlphaMask(picture)
+	if mask.opaqueAt(0, 0) == true || mask.opaqueAt(1, 0) == false {
+		t.Error("alpha mask doesn't split opaque pixels at half alpha")
+	}
+	if mask.opaqueAt(-1, 0) == true || mask.opaqueAt(2, 0) == true || mask.opaqueAt(0, 1) == true {
+		t.Error("alpha mask is opaque outside the picture")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+// boxHits returns a blocked callback for a size by size shell drawn at x, y against one wall.
+func boxHits(size int, wall image.Rectangle) func(x, y int) bool {
+	return func(x, y int) bool {
+		return image.Rect(x, y, x+size, y+size).Overlaps(wall)
+	}
+}
+
+func TestSweepPath(t *testing.T) {
+	thinWall := image.Rect(25, -50, 26, 50)
+	tests := []struct {
+		name        string
+		from        image.Point
+		to          image.Point
+		blocked     func(x, y int) bool
+		wantContact image.Point
+		wantFree    image.Point
+		wantHit     bool
+	}{
+		{"10px shell stopped by a 1px wall", image.Pt(0, 0), image.Pt(40, 0), boxHits(10, thinWall),
+			image.Pt(16, 0), image.Pt(15, 0), true},
+		{"shell jumping a 1px wall in one tick", image.Pt(0, 0), image.Pt(100, 0), boxHits(1, thinWall),
+			image.Pt(25, 0), image.Pt(24, 0), true},
+		{"moving away from the wall", image.Pt(40, 0), image.Pt(80, 0), boxHits(10, thinWall),
+			image.Pt(80, 0), image.Pt(80, 0), false},
+		{"blocked at the start point", image.Pt(20, 0), image.Pt(40, 0), boxHits(10, thinWall),
+			image.Pt(20, 0), image.Pt(20, 0), true},
+		{"first blocked at the end point", image.Pt(0, 0), image.Pt(16, 0), boxHits(10, thinWall),
+			image.Pt(16, 0), image.Pt(15, 0), true},
+		{"diagonal into a corner", image.Pt(0, 0), image.Pt(30, 30), boxHits(10, image.Rect(20, 20, 21, 21)),
+			image.Pt(11, 11), image.Pt(10, 10), true},
+		{"diagonal up and left", image.Pt(60, 60), image.Pt(0, 0), boxHits(10, image.Rect(20, 20, 21, 21)),
+			image.Pt(20, 20), image.Pt(21, 21), true},
+		{"shallow diagonal steps along the longer axis", image.Pt(0, 0), image.Pt(40, 10), boxHits(1, image.Rect(20, 5, 21, 6)),
+			image.Pt(20, 5), image.Pt(19, 5), true},
+		{"not moving", image.Pt(5, 5), image.Pt(5, 5), boxHits(10, thinWall),
+			image.Pt(5, 5), image.Pt(5, 5), false},
+	}
+	for _, test := range tests {
+		contact, free, hit := sweepPath(test.from, test.to, test.blocked)
+		if contact != test.wantContact || free != test.wantFree || hit != test.wantHit {
+			t.Errorf("%s: sweepPath(%v, %v) = %v, %v, %v, want %v, %v, %v", test.name, test.from, test.to,
+				contact, free, hit, test.wantContact, test.wantFree, test.wantHit)
+		}
+	}
+}